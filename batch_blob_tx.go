@@ -29,6 +29,8 @@ func StressBlobTxApp(cliCtx *cli.Context) {
 	chainID := cliCtx.String(TxChainID.Name)
 	calldata := cliCtx.String(TxCalldata.Name)
 	blobPerTx := cliCtx.Uint64(TxBlobCountFlag.Name)
+	parallelism := cliCtx.Int(EncodeParallelismFlag.Name)
+	logTimings := cliCtx.Bool(EncodeLogTimingsFlag.Name)
 
 	value256, err := uint256.FromHex(value)
 	if err != nil {
@@ -108,7 +110,7 @@ func StressBlobTxApp(cliCtx *cli.Context) {
 
 			log.Printf("all preparation done for client %d, start loop sending transactions", i)
 			for {
-				randBlobs := randomBlobs(int(blobPerTx))
+				randBlobs := randomBlobs(int(blobPerTx), EncodeOptions{Parallelism: parallelism, LogTimings: logTimings})
 				subNonuce, err := client.PendingNonceAt(ctx, crypto.PubkeyToAddress(key.PublicKey))
 				if err != nil {
 					log.Panicf("Error getting nonce: %v", err)
@@ -123,12 +125,8 @@ func StressBlobTxApp(cliCtx *cli.Context) {
 					Value:      value256,
 					Data:       calldataBytes,
 					BlobFeeCap: maxFeePerBlobGas256,
-					BlobHashes: randBlobs.versionedHashes,
-					Sidecar: &types.BlobTxSidecar{
-						Commitments: randBlobs.comms,
-						Proofs:      randBlobs.proofs,
-						Blobs:       randBlobs.blobs,
-					},
+					BlobHashes: randBlobs.VersionedHashes(),
+					Sidecar:    randBlobs.Sidecar(),
 				})
 				signedTx, err := types.SignTx(tx, types.NewCancunSigner(chainId), key)
 				if err != nil {
@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	das "github.com/DillLabs/dill-das"
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/urfave/cli"
+)
+
+// benchSeed seeds the benchmark payloads so that every run measures the same
+// blobs and results stay comparable.
+const benchSeed = 1
+
+// benchReport is the JSON output of the bench command. The environment is
+// recorded so that results are only compared between like machines.
+type benchReport struct {
+	GoVersion  string        `json:"go_version"`
+	GOOS       string        `json:"goos"`
+	GOARCH     string        `json:"goarch"`
+	NumCPU     int           `json:"num_cpu"`
+	Iterations int           `json:"iterations"`
+	Results    []benchResult `json:"results"`
+}
+
+type benchResult struct {
+	Op          string  `json:"op"`
+	Blobs       int     `json:"blobs"`
+	Parallelism int     `json:"parallelism"`
+	Ops         int     `json:"ops"`
+	OpsPerSec   float64 `json:"ops_per_sec"`
+	P50         int64   `json:"p50_ns"`
+	P99         int64   `json:"p99_ns"`
+	AllocsPerOp uint64  `json:"allocs_per_op"`
+	BytesPerOp  uint64  `json:"bytes_per_op"`
+}
+
+// benchOp is a benchmarked operation on blob i.
+type benchOp struct {
+	name string
+	run  func(i int) error
+}
+
+func BenchApp(cliCtx *cli.Context) error {
+	blobCounts, err := parseIntList(cliCtx.String(BenchBlobCountsFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid blob counts: %v", err)
+	}
+	parallelisms, err := parseIntList(cliCtx.String(BenchParallelismFlag.Name))
+	if err != nil {
+		return fmt.Errorf("invalid parallelism: %v", err)
+	}
+	iterations := cliCtx.Int(BenchIterationsFlag.Name)
+	jsonFile := cliCtx.String(BenchJSONFlag.Name)
+	if iterations < 1 {
+		return fmt.Errorf("invalid iterations %d", iterations)
+	}
+
+	report := benchReport{
+		GoVersion:  runtime.Version(),
+		GOOS:       runtime.GOOS,
+		GOARCH:     runtime.GOARCH,
+		NumCPU:     runtime.NumCPU(),
+		Iterations: iterations,
+	}
+	for _, n := range blobCounts {
+		ops, err := newBenchOps(n)
+		if err != nil {
+			return err
+		}
+		for _, p := range parallelisms {
+			for _, op := range ops {
+				res, err := runBench(op, n, p, iterations)
+				if err != nil {
+					return fmt.Errorf("%s with %d blobs: %v", op.name, n, err)
+				}
+				log.Printf("%s: %d blobs, %d workers, %.1f ops/s", res.Op, res.Blobs, res.Parallelism, res.OpsPerSec)
+				report.Results = append(report.Results, res)
+			}
+		}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "op\tblobs\tparallelism\tops/s\tp50\tp99\tallocs/op\tB/op\t")
+	for _, r := range report.Results {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%.1f\t%v\t%v\t%d\t%d\t\n",
+			r.Op, r.Blobs, r.Parallelism, r.OpsPerSec, time.Duration(r.P50), time.Duration(r.P99), r.AllocsPerOp, r.BytesPerOp)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if jsonFile == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(report, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(jsonFile, b, 0644); err != nil {
+		return fmt.Errorf("error writing bench results: %v", err)
+	}
+	return nil
+}
+
+// newBenchOps prepares the benchmarked operations over n blobs of
+// deterministic data. Inputs of every operation are computed up front so that
+// only the operation itself is measured.
+func newBenchOps(n int) ([]benchOp, error) {
+	rng := rand.New(rand.NewSource(benchSeed))
+	payloads := make([][]byte, n)
+	blobs := make([]kzg4844.Blob, n)
+	commits := make([]kzg4844.Commitment, n)
+	for i := range payloads {
+		payloads[i] = make([]byte, blobPayloadSize)
+		rng.Read(payloads[i])
+		blobs[i] = encodeBlob(payloads[i], BlobFormatPacked31, CompressionNone)
+		commit, err := kzg4844.BlobToCommitment(blobs[i])
+		if err != nil {
+			return nil, err
+		}
+		commits[i] = commit
+	}
+	var point kzg4844.Point
+	point[len(point)-1] = 0x05
+
+	return []benchOp{
+		{"encodeBlobs", func(i int) error {
+			encodeBlobs(payloads[i], BlobFormatPacked31, CompressionNone)
+			return nil
+		}},
+		{"BlobToCommitment", func(i int) error {
+			_, err := kzg4844.BlobToCommitment(blobs[i])
+			return err
+		}},
+		{"ComputeBlobProof", func(i int) error {
+			_, err := kzg4844.ComputeBlobProof(blobs[i], commits[i])
+			return err
+		}},
+		{"ComputeProof", func(i int) error {
+			_, _, err := kzg4844.ComputeProof(blobs[i], point)
+			return err
+		}},
+		{"BlobToSegmentsProofOnly", func(i int) error {
+			_, err := das.BlobToSegmentsProofOnly(blobs[i][:])
+			return err
+		}},
+	}, nil
+}
+
+// runBench runs op over n blobs with parallelism workers, iterations times.
+// Allocations are read from the runtime totals and so include anything else
+// running concurrently.
+func runBench(op benchOp, n, parallelism, iterations int) (benchResult, error) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	var (
+		total     = n * iterations
+		latencies = make([]time.Duration, total)
+		errs      = make([]error, total)
+		jobs      = make(chan int)
+		wg        sync.WaitGroup
+		before    runtime.MemStats
+		after     runtime.MemStats
+	)
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				t := time.Now()
+				errs[j] = op.run(j % n)
+				latencies[j] = time.Since(t)
+			}
+		}()
+	}
+	for j := 0; j < total; j++ {
+		jobs <- j
+	}
+	close(jobs)
+	wg.Wait()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	for _, err := range errs {
+		if err != nil {
+			return benchResult{}, err
+		}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	return benchResult{
+		Op:          op.name,
+		Blobs:       n,
+		Parallelism: parallelism,
+		Ops:         total,
+		OpsPerSec:   float64(total) / elapsed.Seconds(),
+		P50:         int64(percentile(latencies, 50)),
+		P99:         int64(percentile(latencies, 99)),
+		AllocsPerOp: (after.Mallocs - before.Mallocs) / uint64(total),
+		BytesPerOp:  (after.TotalAlloc - before.TotalAlloc) / uint64(total),
+	}, nil
+}
+
+// percentile returns the p-th percentile of sorted latencies, nearest rank.
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// parseIntList parses a comma separated list of positive integers.
+func parseIntList(s string) ([]int, error) {
+	var out []int
+	for _, f := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 {
+			return nil, fmt.Errorf("%d is not positive", n)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
@@ -1,12 +1,16 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"math/big"
 	"os"
+	"path/filepath"
 	"time"
 
 	ethereum "github.com/DillLabs/dill-execution"
@@ -32,28 +36,104 @@ func BlobTxApp(cliCtx *cli.Context) error {
 	chainID := cliCtx.String(TxChainID.Name)
 	calldata := cliCtx.String(TxCalldata.Name)
 	blobCnt := cliCtx.Uint64(TxBlobCountFlag.Name)
+	maxBlobsPerTx := cliCtx.Uint64(TxMaxBlobsPerTxFlag.Name)
+	canonical := cliCtx.Bool(TxCanonicalFlag.Name)
+	pad := cliCtx.Bool(TxPadBlobFlag.Name)
+	parallelism := cliCtx.Int(EncodeParallelismFlag.Name)
+	logTimings := cliCtx.Bool(EncodeLogTimingsFlag.Name)
+	offline := cliCtx.Bool(TxOfflineFlag.Name)
+	rawOutput := cliCtx.String(TxRawOutputFlag.Name)
+	compression, err := ParseCompression(cliCtx.String(EncodeCompressionFlag.Name))
+	if err != nil {
+		return err
+	}
+	format, err := ParseBlobFormat(cliCtx.String(EncodePackingFlag.Name))
+	if err != nil {
+		return err
+	}
+	cache, err := bundleCacheFromFlags(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	if offline {
+		if nonce == -1 {
+			return errors.New("offline signing requires an explicit nonce")
+		}
+		// defaults are no substitute for the values of the target chain, and
+		// an empty gas-price would be fetched from the node
+		for _, name := range []string{TxChainID.Name, TxGasPriceFlag.Name, TxPriorityGasPrice.Name, TxMaxFeePerBlobGas.Name} {
+			if !cliCtx.IsSet(name) || cliCtx.String(name) == "" {
+				return fmt.Errorf("offline signing requires an explicit %s", name)
+			}
+		}
+	}
 
 	value256, err := uint256.FromHex(value)
 	if err != nil {
 		return fmt.Errorf("invalid value param: %v", err)
 	}
-	var data []byte
+	var stream *BlobStream
 	if file == "" {
-		data = RandomFrData(4096 * 32 * int(blobCnt))
+		stream, err = NewBlobStream(bytes.NewReader(RandomFrData(4096*32*int(blobCnt))), EncodeOptions{Canonical: true, Parallelism: parallelism, LogTimings: logTimings})
+		maxBlobsPerTx = blobCnt
 	} else {
-		data, err = os.ReadFile(file)
-		if err != nil {
-			return fmt.Errorf("error reading blob file: %v", err)
+		r, rerr := openInput(file)
+		if rerr != nil {
+			return fmt.Errorf("error reading blob file: %v", rerr)
+		}
+		defer r.Close()
+		stream, err = NewBlobStream(r, EncodeOptions{
+			Canonical:   canonical,
+			Pad:         pad,
+			Parallelism: parallelism,
+			Compression: compression,
+			Format:      format,
+			Cache:       cache,
+			LogTimings:  logTimings,
+		})
+	}
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	if canonical && file != "" {
+		// check every blob before the first tx is sent or written
+		if err := stream.Preload(); err != nil {
+			return fmt.Errorf("invalid blob file: %v", err)
 		}
 	}
-	log.Printf("file size: %d\n", len(data))
 
-	chainId, _ := new(big.Int).SetString(chainID, 0)
+	chainId, ok := new(big.Int).SetString(chainID, 0)
+	if !ok {
+		return fmt.Errorf("invalid chain-id %q", chainID)
+	}
 
-	ctx := context.Background()
-	client, err := ethclient.DialContext(ctx, addr)
-	if err != nil {
-		log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+	var (
+		ctx     = context.Background()
+		client  *ethclient.Client
+		out     io.Writer = os.Stdout
+		rawFile *os.File
+	)
+	if offline {
+		if rawOutput != "" {
+			// the txs go to a temporary file renamed once all are signed, so
+			// a failure part way never leaves a truncated raw-tx-output
+			rawFile, err = os.CreateTemp(filepath.Dir(rawOutput), filepath.Base(rawOutput)+".tmp-*")
+			if err != nil {
+				return fmt.Errorf("error creating raw tx output: %v", err)
+			}
+			defer func() {
+				rawFile.Close()
+				os.Remove(rawFile.Name())
+			}()
+			out = rawFile
+		}
+	} else {
+		client, err = ethclient.DialContext(ctx, addr)
+		if err != nil {
+			log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+		}
 	}
 
 	key, err := crypto.HexToECDSA(prv)
@@ -100,60 +180,92 @@ func BlobTxApp(cliCtx *cli.Context) error {
 		return fmt.Errorf("%w: invalid max_fee_per_blob_gas", err)
 	}
 
-	blobs, commitments, proofs, _, versionedHashes, err := EncodeBlobs(data, file == "")
-	if err != nil {
-		log.Fatalf("failed to compute commitments: %v", err)
-	}
-
 	calldataBytes, err := common.ParseHexOrString(calldata)
 	if err != nil {
 		log.Fatalf("failed to parse calldata: %v", err)
 	}
 
-	tx := types.NewTx(&types.BlobTx{
-		ChainID:    uint256.MustFromBig(chainId),
-		Nonce:      uint64(nonce),
-		GasTipCap:  priorityGasPrice256,
-		GasFeeCap:  gasPrice256,
-		Gas:        gasLimit,
-		To:         to,
-		Value:      value256,
-		Data:       calldataBytes,
-		BlobFeeCap: maxFeePerBlobGas256,
-		BlobHashes: versionedHashes,
-		Sidecar:    &types.BlobTxSidecar{Blobs: blobs, Commitments: commitments, Proofs: proofs},
-	})
-	signedTx, _ := types.SignTx(tx, types.NewCancunSigner(chainId), key)
+	for {
+		bundle, err := stream.Next(int(maxBlobsPerTx))
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to compute commitments: %v", err)
+		}
 
-	log.Printf("Commitments: %v\n", fmt.Sprintf("0x%x", signedTx.BlobTxSidecar().Commitments))
+		tx := types.NewTx(&types.BlobTx{
+			ChainID:    uint256.MustFromBig(chainId),
+			Nonce:      uint64(nonce),
+			GasTipCap:  priorityGasPrice256,
+			GasFeeCap:  gasPrice256,
+			Gas:        gasLimit,
+			To:         to,
+			Value:      value256,
+			Data:       calldataBytes,
+			BlobFeeCap: maxFeePerBlobGas256,
+			BlobHashes: bundle.VersionedHashes(),
+			Sidecar:    bundle.Sidecar(),
+		})
+		signedTx, err := types.SignTx(tx, types.NewCancunSigner(chainId), key)
+		if err != nil {
+			return fmt.Errorf("failed to sign transaction: %v", err)
+		}
 
-	log.Printf("GasTipCap: %v, BlobGasFeeCap: %v, GasFeeCap: %v\n",
-		signedTx.GasTipCap(), signedTx.BlobGasFeeCap(), signedTx.GasFeeCap())
+		if offline {
+			if err := writeRawTx(out, signedTx); err != nil {
+				return err
+			}
+			log.Printf("signed transaction offline. nonce=%d txhash=%v", nonce, signedTx.Hash())
+			nonce++
+			continue
+		}
 
-	err = client.SendTransaction(context.Background(), signedTx)
+		log.Printf("Commitments: %v\n", fmt.Sprintf("0x%x", signedTx.BlobTxSidecar().Commitments))
 
-	if err != nil {
-		log.Fatalf("failed to send transaction: %v", err)
-	} else {
-		log.Printf("successfully sent transaction. txhash=%v", signedTx.Hash())
-	}
+		log.Printf("GasTipCap: %v, BlobGasFeeCap: %v, GasFeeCap: %v\n",
+			signedTx.GasTipCap(), signedTx.BlobGasFeeCap(), signedTx.GasFeeCap())
 
-	//var receipt *types.Receipt
-	for {
-		_, err = client.TransactionReceipt(context.Background(), signedTx.Hash())
-		if err == ethereum.NotFound {
-			time.Sleep(1 * time.Second)
-		} else if err != nil {
-			if _, ok := err.(*json.UnmarshalTypeError); ok {
-				// TODO: ignore other errors for now. Some clients are treating the blobGasUsed as big.Int rather than uint64
+		err = client.SendTransaction(context.Background(), signedTx)
+
+		if err != nil {
+			log.Fatalf("failed to send transaction: %v", err)
+		} else {
+			log.Printf("successfully sent transaction. txhash=%v", signedTx.Hash())
+		}
+
+		//var receipt *types.Receipt
+		for {
+			_, err = client.TransactionReceipt(context.Background(), signedTx.Hash())
+			if err == ethereum.NotFound {
+				time.Sleep(1 * time.Second)
+			} else if err != nil {
+				if _, ok := err.(*json.UnmarshalTypeError); ok {
+					// TODO: ignore other errors for now. Some clients are treating the blobGasUsed as big.Int rather than uint64
+					break
+				}
+			} else {
 				break
 			}
-		} else {
-			break
 		}
-	}
 
-	log.Printf("Transaction included. nonce=%d hash=%v", nonce, tx.Hash())
-	//log.Printf("Transaction included. nonce=%d hash=%v, block=%d", nonce, tx.Hash(), receipt.BlockNumber.Int64())
+		log.Printf("Transaction included. nonce=%d hash=%v", nonce, tx.Hash())
+		//log.Printf("Transaction included. nonce=%d hash=%v, block=%d", nonce, tx.Hash(), receipt.BlockNumber.Int64())
+		nonce++
+	}
+	if rawFile != nil {
+		if err := rawFile.Close(); err != nil {
+			return fmt.Errorf("error writing raw tx output: %v", err)
+		}
+		if err := os.Rename(rawFile.Name(), rawOutput); err != nil {
+			return fmt.Errorf("error writing raw tx output: %v", err)
+		}
+		log.Printf("wrote signed txs to %s", rawOutput)
+	}
+	log.Printf("file size: %d, blobs: %d\n", stream.Size(), stream.Blobs())
+	if file != "" && !canonical && compression != CompressionNone {
+		log.Printf("%v compressed size: %d, blobs saved: %d\n",
+			compression, stream.PayloadSize(), format.blobsNeeded(stream.Size())-stream.Blobs())
+	}
 	return nil
 }
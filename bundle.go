@@ -0,0 +1,99 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DillLabs/dill-execution/common"
+	"github.com/DillLabs/dill-execution/core/types"
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+)
+
+// BlobBundle holds a set of blobs together with the KZG data computed for
+// them. All slices are indexed by blob. Proofs and the segment proofs are nil
+// unless they were requested in EncodeOptions.
+type BlobBundle struct {
+	Blobs       []kzg4844.Blob
+	Commitments []kzg4844.Commitment
+	Proofs      []kzg4844.Proof
+
+	segmentProofs   [][]kzg4844.Proof
+	versionedHashes []common.Hash
+}
+
+// Len returns the number of blobs in the bundle.
+func (b *BlobBundle) Len() int {
+	return len(b.Blobs)
+}
+
+// Sidecar returns the blob tx sidecar carrying the bundle.
+func (b *BlobBundle) Sidecar() *types.BlobTxSidecar {
+	return &types.BlobTxSidecar{
+		Blobs:       b.Blobs,
+		Commitments: b.Commitments,
+		Proofs:      b.Proofs,
+	}
+}
+
+// VersionedHashes returns the versioned hash of every commitment.
+func (b *BlobBundle) VersionedHashes() []common.Hash {
+	return b.versionedHashes
+}
+
+// SegmentProofs returns the marshaled dill-das segment proofs of every blob,
+// or nil if they were not computed.
+func (b *BlobBundle) SegmentProofs() [][]kzg4844.Proof {
+	return b.segmentProofs
+}
+
+// Verify checks the KZG data present in the bundle: every blob against its
+// commitment, with the blob proof if the bundle has proofs and by recomputing
+// the commitment otherwise, and every versioned hash against its commitment.
+// Segment proofs are not checked.
+func (b *BlobBundle) Verify() error {
+	return b.verify(true)
+}
+
+// verify implements Verify. Without recompute, the commitments of a bundle
+// without blob proofs are trusted and only the versioned hashes are checked,
+// for bundles whose commitments are known to belong to their blobs.
+func (b *BlobBundle) verify(recompute bool) error {
+	if len(b.Commitments) != len(b.Blobs) || len(b.versionedHashes) != len(b.Blobs) ||
+		(b.Proofs != nil && len(b.Proofs) != len(b.Blobs)) ||
+		(b.segmentProofs != nil && len(b.segmentProofs) != len(b.Blobs)) {
+		return errors.New("bundle has mismatched blob, commitment, proof and versioned hash counts")
+	}
+	for i := range b.Blobs {
+		if b.Proofs != nil {
+			if err := kzg4844.VerifyBlobProof(b.Blobs[i], b.Commitments[i], b.Proofs[i]); err != nil {
+				return fmt.Errorf("blob %d: %w", i, err)
+			}
+		} else if recompute {
+			commit, err := kzg4844.BlobToCommitment(b.Blobs[i])
+			if err != nil {
+				return fmt.Errorf("blob %d: %w", i, err)
+			}
+			if commit != b.Commitments[i] {
+				return fmt.Errorf("blob %d: commitment %x does not match blob", i, b.Commitments[i])
+			}
+		}
+		if b.versionedHashes[i] != kZGToVersionedHash(b.Commitments[i]) {
+			return fmt.Errorf("blob %d: versioned hash %v does not match commitment", i, b.versionedHashes[i])
+		}
+	}
+	return nil
+}
+
+// covers reports whether b holds all the KZG data requested by opts.
+func (b *BlobBundle) covers(opts EncodeOptions) bool {
+	return (opts.SkipBlobProofs || b.Proofs != nil) && (!opts.SegmentProofs || b.segmentProofs != nil)
+}
+
+// blobsToBytes returns the blobs as byte slices sharing their memory.
+func blobsToBytes(blobs []kzg4844.Blob) [][]byte {
+	out := make([][]byte, len(blobs))
+	for i := range blobs {
+		out[i] = blobs[i][:]
+	}
+	return out
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DillLabs/dill-execution/common"
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/urfave/cli"
+)
+
+// bundleCacheVersion is bumped whenever the cached entry layout or the way
+// KZG data is computed changes, so stale entries are never loaded.
+const bundleCacheVersion = 3
+
+// DefaultBundleCacheSize is the size the cache directory is kept below unless
+// another limit is set.
+const DefaultBundleCacheSize = 256 << 20
+
+// BundleCache stores computed blob bundles on disk so that encoding the same
+// input again, e.g. to resend a stuck tx, skips the KZG computation. Entries
+// are keyed by the SHA-256 of the blobs, which are fully determined by the
+// input, the codec version and the encode options. Once the entries exceed
+// maxSize bytes, the least recently used ones are removed.
+type BundleCache struct {
+	dir     string
+	maxSize int64
+}
+
+// bundleCacheEntry is the gob encoded form of a BlobBundle. The blobs are not
+// stored, the caller passes them to Load and they are covered by the key. On
+// disk the entry is preceded by the SHA-256 of its encoding, which catches
+// corrupted KZG data, segment proofs included, without recomputing it.
+type bundleCacheEntry struct {
+	Commitments     []kzg4844.Commitment
+	Proofs          []kzg4844.Proof
+	SegmentProofs   [][]kzg4844.Proof
+	VersionedHashes []common.Hash
+}
+
+// NewBundleCache returns a cache storing its entries in dir, using at most
+// maxSize bytes. A maxSize of 0 or less does not limit the cache.
+func NewBundleCache(dir string, maxSize int64) *BundleCache {
+	return &BundleCache{dir: dir, maxSize: maxSize}
+}
+
+// DefaultBundleCacheDir returns the cache directory used when none is set,
+// below the user's cache directory.
+func DefaultBundleCacheDir() (string, error) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "dill-blob-utils", "bundles"), nil
+}
+
+func (c *BundleCache) path(blobs []kzg4844.Blob) string {
+	h := sha256.New()
+	h.Write([]byte{bundleCacheVersion, blobCodecVersion})
+	for i := range blobs {
+		h.Write(blobs[i][:])
+	}
+	return filepath.Join(c.dir, fmt.Sprintf("%x.gob", h.Sum(nil)))
+}
+
+// Load returns the cached bundle for blobs, or false if there is none or it
+// fails verification. The entry is keyed by the blobs, so commitments are not
+// recomputed, only checked with the blob proofs if the entry has them.
+func (c *BundleCache) Load(blobs []kzg4844.Blob) (*BlobBundle, bool) {
+	path := c.path(blobs)
+	entry, err := readBundleCacheEntry(path)
+	if os.IsNotExist(err) {
+		return nil, false
+	}
+	if err != nil {
+		log.Printf("ignoring corrupt bundle cache entry: %v", err)
+		return nil, false
+	}
+	// blob proofs and segment proofs are only present if they were computed
+	if len(entry.Commitments) != len(blobs) || len(entry.VersionedHashes) != len(blobs) ||
+		(entry.Proofs != nil && len(entry.Proofs) != len(blobs)) ||
+		(entry.SegmentProofs != nil && len(entry.SegmentProofs) != len(blobs)) {
+		return nil, false
+	}
+	bundle := &BlobBundle{
+		Blobs:           blobs,
+		Commitments:     entry.Commitments,
+		Proofs:          entry.Proofs,
+		segmentProofs:   entry.SegmentProofs,
+		versionedHashes: entry.VersionedHashes,
+	}
+	if err := bundle.verify(false); err != nil {
+		log.Printf("ignoring invalid bundle cache entry: %v", err)
+		return nil, false
+	}
+	// the modification time orders the entries for eviction
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return bundle, true
+}
+
+// Store writes bundle to the cache. Blob proofs and segment proofs missing
+// from bundle are kept from an existing entry, so a less complete bundle never
+// replaces a more complete one, without verifying them again: Load checks the
+// entry. It is written to a temporary file first, so a concurrent Load never
+// sees a partial entry. Least recently used entries are then removed until the
+// cache fits its size limit.
+func (c *BundleCache) Store(bundle *BlobBundle) error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	entry := bundleCacheEntry{
+		Commitments:     bundle.Commitments,
+		Proofs:          bundle.Proofs,
+		SegmentProofs:   bundle.segmentProofs,
+		VersionedHashes: bundle.versionedHashes,
+	}
+	if old, err := readBundleCacheEntry(c.path(bundle.Blobs)); err == nil {
+		if entry.Proofs == nil && len(old.Proofs) == bundle.Len() {
+			entry.Proofs = old.Proofs
+		}
+		if entry.SegmentProofs == nil && len(old.SegmentProofs) == bundle.Len() {
+			entry.SegmentProofs = old.SegmentProofs
+		}
+	}
+	var payload bytes.Buffer
+	if err := gob.NewEncoder(&payload).Encode(entry); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(payload.Bytes())
+	data := append(sum[:], payload.Bytes()...)
+	f, err := os.CreateTemp(c.dir, "bundle-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	if err := os.Rename(f.Name(), c.path(bundle.Blobs)); err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return c.evict()
+}
+
+// readBundleCacheEntry reads the entry written by Store to path after checking
+// its checksum.
+func readBundleCacheEntry(path string) (*bundleCacheEntry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < sha256.Size || sha256.Sum256(data[sha256.Size:]) != [sha256.Size]byte(data[:sha256.Size]) {
+		return nil, errors.New("checksum mismatch")
+	}
+	var entry bundleCacheEntry
+	if err := gob.NewDecoder(bytes.NewReader(data[sha256.Size:])).Decode(&entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// evict removes the least recently used entries until they take at most
+// maxSize bytes. Temporary files of concurrent writers are left alone.
+func (c *BundleCache) evict() error {
+	if c.maxSize <= 0 {
+		return nil
+	}
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+	var (
+		entries []os.FileInfo
+		size    int64
+	)
+	for _, e := range dirEntries {
+		if !e.Type().IsRegular() || !strings.HasSuffix(e.Name(), ".gob") || !isBundleCacheFile(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, info)
+		size += info.Size()
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ModTime().Before(entries[j].ModTime()) })
+	for _, info := range entries {
+		if size <= c.maxSize {
+			break
+		}
+		if err := os.Remove(filepath.Join(c.dir, info.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		size -= info.Size()
+	}
+	return nil
+}
+
+// Clear removes all cached entries and leftover temporary files. Nothing else
+// in the cache directory is touched, since it may be set by the user.
+func (c *BundleCache) Clear() error {
+	entries, err := os.ReadDir(c.dir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if !e.Type().IsRegular() || !isBundleCacheFile(e.Name()) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(c.dir, e.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isBundleCacheFile reports whether name is a cache entry, named by its hex
+// encoded SHA-256 key, or a temporary file written by Store.
+func isBundleCacheFile(name string) bool {
+	if strings.HasPrefix(name, "bundle-") && strings.HasSuffix(name, ".tmp") {
+		return true
+	}
+	key, ok := strings.CutSuffix(name, ".gob")
+	if !ok || len(key) != 2*sha256.Size {
+		return false
+	}
+	_, err := hex.DecodeString(key)
+	return err == nil
+}
+
+// bundleFor returns the bundle of blobs from opts.Cache if present with all
+// the requested KZG data, and computes and caches it otherwise. Cache write
+// failures are only logged.
+func bundleFor(blobs []kzg4844.Blob, opts EncodeOptions) (*BlobBundle, error) {
+	if opts.Cache == nil {
+		return computeBlobBundle(blobs, opts)
+	}
+	if bundle, ok := opts.Cache.Load(blobs); ok && bundle.covers(opts) {
+		log.Printf("kzg: %d blobs loaded from cache %s", len(blobs), opts.Cache.dir)
+		return bundle, nil
+	}
+	bundle, err := computeBlobBundle(blobs, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := opts.Cache.Store(bundle); err != nil {
+		log.Printf("failed to write bundle cache: %v", err)
+	}
+	return bundle, nil
+}
+
+// bundleCacheFromFlags returns the cache selected by the cache flags, or nil
+// if caching is disabled. The cache is emptied first if requested.
+func bundleCacheFromFlags(cliCtx *cli.Context) (*BundleCache, error) {
+	dir := cliCtx.String(CacheDirFlag.Name)
+	noCache := cliCtx.Bool(NoCacheFlag.Name)
+	clearCache := cliCtx.Bool(ClearCacheFlag.Name)
+	if dir == "" {
+		var err error
+		if dir, err = DefaultBundleCacheDir(); err != nil {
+			if clearCache {
+				return nil, fmt.Errorf("error locating bundle cache: %v", err)
+			}
+			log.Printf("bundle cache disabled: %v", err)
+			return nil, nil
+		}
+	}
+	cache := NewBundleCache(dir, cliCtx.Int64(CacheMaxSizeFlag.Name)<<20)
+	if clearCache {
+		if err := cache.Clear(); err != nil {
+			return nil, fmt.Errorf("error clearing bundle cache: %v", err)
+		}
+		log.Printf("cleared bundle cache %s", dir)
+	}
+	if noCache {
+		return nil, nil
+	}
+	return cache, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBundleCache(t *testing.T) {
+	dir := t.TempDir()
+	cache := NewBundleCache(dir, 0)
+	blobs := encodeBlobs(makeBlob(2*blobPayloadSize), BlobFormatPacked31, CompressionNone)
+	bundle, err := computeBlobBundle(blobs, EncodeOptions{SegmentProofs: true})
+	require.NoError(t, err)
+
+	_, ok := cache.Load(blobs)
+	require.False(t, ok)
+	require.NoError(t, cache.Store(bundle))
+
+	loaded, ok := cache.Load(blobs)
+	require.True(t, ok)
+	require.Equal(t, bundle, loaded)
+
+	_, ok = cache.Load(blobs[:1])
+	require.False(t, ok)
+
+	// a less complete bundle keeps the proofs already cached
+	partial := *bundle
+	partial.Proofs = nil
+	partial.segmentProofs = nil
+	require.NoError(t, cache.Store(&partial))
+	loaded, ok = cache.Load(blobs)
+	require.True(t, ok)
+	require.Equal(t, bundle, loaded)
+
+	// bundles without segment proofs only cover requests that skip them
+	require.NoError(t, cache.Clear())
+	require.NoError(t, cache.Store(&partial))
+	loaded, ok = cache.Load(blobs)
+	require.True(t, ok)
+	require.Nil(t, loaded.Proofs)
+	require.Nil(t, loaded.SegmentProofs())
+	require.True(t, loaded.covers(EncodeOptions{SkipBlobProofs: true}))
+	require.False(t, loaded.covers(EncodeOptions{}))
+	require.False(t, loaded.covers(EncodeOptions{SkipBlobProofs: true, SegmentProofs: true}))
+
+	// entries failing verification are not loaded
+	tampered := *bundle
+	tampered.Commitments = append(tampered.Commitments[:0:0], bundle.Commitments...)
+	tampered.Commitments[0][0] ^= 0x01
+	require.NoError(t, cache.Clear())
+	require.NoError(t, cache.Store(&tampered))
+	_, ok = cache.Load(blobs)
+	require.False(t, ok)
+
+	// corrupted entries, e.g. in the segment proofs, fail the checksum
+	require.NoError(t, cache.Clear())
+	require.NoError(t, cache.Store(bundle))
+	data, err := os.ReadFile(cache.path(blobs))
+	require.NoError(t, err)
+	data[len(data)-10] ^= 0x01
+	require.NoError(t, os.WriteFile(cache.path(blobs), data, 0644))
+	_, ok = cache.Load(blobs)
+	require.False(t, ok)
+
+	// the least recently used entries are removed beyond the size limit
+	require.NoError(t, cache.Clear())
+	require.NoError(t, cache.Store(bundle))
+	info, err := os.Stat(cache.path(blobs))
+	require.NoError(t, err)
+	old := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(cache.path(blobs), old, old))
+	otherBlobs := append([]kzg4844.Blob(nil), blobs...)
+	otherBlobs[0][100] ^= 0x01
+	other, err := computeBlobBundle(otherBlobs, EncodeOptions{SegmentProofs: true})
+	require.NoError(t, err)
+	small := NewBundleCache(dir, info.Size()+info.Size()/2)
+	require.NoError(t, small.Store(other))
+	_, ok = small.Load(blobs)
+	require.False(t, ok)
+	_, ok = small.Load(otherBlobs)
+	require.True(t, ok)
+
+	// only cache entries are cleared
+	notes := filepath.Join(dir, "notes.gob")
+	require.NoError(t, os.WriteFile(notes, []byte("keep"), 0644))
+	require.NoError(t, cache.Clear())
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "notes.gob", entries[0].Name())
+	require.NoError(t, NewBundleCache(filepath.Join(dir, "missing"), 0).Clear())
+}
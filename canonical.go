@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DillLabs/dill-blob-utils/hex"
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/DillLabs/dill-execution/params"
+)
+
+// blsModulus is the BLS12-381 scalar field modulus, big endian. Every field
+// element of a blob must be below it.
+var blsModulus = hex.MustDecodeHex("0x73eda753299d7d483339d80809a1d80553bda402fffe5bfeffffffff00000001")
+
+// maxReportedFieldElements caps the number of offending field elements listed
+// in a non-canonical blob error.
+const maxReportedFieldElements = 16
+
+var errNoBlobData = errors.New("no blob data")
+
+// canonicalBlobs splits raw blob data into blobs and checks that every field
+// element is below the BLS modulus. A trailing partial blob is zero padded
+// with pad set and an error otherwise.
+func canonicalBlobs(data []byte, pad bool) ([]kzg4844.Blob, error) {
+	if len(data) == 0 {
+		return nil, errNoBlobData
+	}
+	if rem := len(data) % blobSize; rem != 0 {
+		if !pad {
+			return nil, fmt.Errorf("trailing %d bytes do not fill a blob, blob data must be a multiple of %d bytes", rem, blobSize)
+		}
+		data = append(data[:len(data):len(data)], make([]byte, blobSize-rem)...)
+	}
+	blobs := make([]kzg4844.Blob, len(data)/blobSize)
+	for i := range blobs {
+		blobs[i] = kzg4844.Blob(data[i*blobSize : (i+1)*blobSize])
+	}
+	if err := checkCanonical(blobs, 0); err != nil {
+		return nil, err
+	}
+	return blobs, nil
+}
+
+// checkCanonical returns an error listing every field element of blobs that
+// is not below the BLS modulus. Blobs are numbered starting at first.
+func checkCanonical(blobs []kzg4844.Blob, first int) error {
+	var bad []string
+	count := 0
+	for i := range blobs {
+		for j := 0; j < params.BlobTxFieldElementsPerBlob; j++ {
+			if bytes.Compare(blobs[i][j*fieldElementSize:(j+1)*fieldElementSize], blsModulus) < 0 {
+				continue
+			}
+			count++
+			if len(bad) < maxReportedFieldElements {
+				bad = append(bad, fmt.Sprintf("blob %d element %d", first+i, j))
+			}
+		}
+	}
+	if count == 0 {
+		return nil
+	}
+	if count > len(bad) {
+		bad = append(bad, fmt.Sprintf("and %d more", count-len(bad)))
+	}
+	return fmt.Errorf("%d field elements are not below the BLS modulus: %s", count, strings.Join(bad, ", "))
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalBlobs(t *testing.T) {
+	_, err := canonicalBlobs(nil, false)
+	require.ErrorIs(t, err, errNoBlobData)
+	_, err = canonicalBlobs(nil, true)
+	require.ErrorIs(t, err, errNoBlobData)
+
+	data := RandomFrData(blobSize + 100)
+	_, err = canonicalBlobs(data, false)
+	require.Error(t, err)
+
+	blobs, err := canonicalBlobs(data, true)
+	require.NoError(t, err)
+	require.Len(t, blobs, 2)
+	require.Equal(t, data[blobSize:], blobs[1][:100])
+	require.Equal(t, make([]byte, blobSize-100), blobs[1][100:])
+}
+
+func TestCanonicalBlobsModulus(t *testing.T) {
+	data := RandomFrData(2 * blobSize)
+	belowModulus := bytes.Clone(blsModulus)
+	belowModulus[31]--
+	copy(data[5*fieldElementSize:], belowModulus)
+	_, err := canonicalBlobs(data, false)
+	require.NoError(t, err)
+
+	copy(data[7*fieldElementSize:], blsModulus)
+	copy(data[blobSize+3*fieldElementSize:], bytes.Repeat([]byte{0xff}, fieldElementSize))
+	_, err = canonicalBlobs(data, false)
+	require.EqualError(t, err, "2 field elements are not below the BLS modulus: blob 0 element 7, blob 1 element 3")
+}
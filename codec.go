@@ -0,0 +1,319 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/DillLabs/dill-execution/params"
+)
+
+// Every blob produced by encodeBlobs starts with a header field element:
+//
+//	byte  0     0x00, keeps the element below the BLS modulus
+//	bytes 1-4   magic "DILL"
+//	byte  5     codec version
+//	byte  6     payload format, see BlobFormat
+//	bytes 7-14  number of payload bytes carried by this blob, big endian
+//	byte  15    compression of the whole payload
+//
+// With BlobFormatPacked31 the remaining field elements carry 31 payload bytes
+// each in bytes 1-31, byte 0 is always zero. BlobFormatPacked254 treats the
+// payload as a bit stream and fills the low 254 bits of every remaining field
+// element, so 4 field elements carry 127 bytes. Each blob is self-describing, so a payload split over
+// several blobs is recovered by decoding the blobs in order, concatenating
+// the results and decompressing them.
+const (
+	blobCodecVersion = 0x01
+
+	fieldElementSize = 32
+	blobSize         = params.BlobTxFieldElementsPerBlob * fieldElementSize
+
+	// blobPayloadSize is the number of payload bytes a single blob can carry
+	// after the header field element with BlobFormatPacked31.
+	blobPayloadSize = (params.BlobTxFieldElementsPerBlob - 1) * 31
+	// blobPayloadSize254 is the same for BlobFormatPacked254.
+	blobPayloadSize254 = (params.BlobTxFieldElementsPerBlob - 1) * 254 / 8
+
+	blobHeaderMagicOffset   = 1
+	blobHeaderVersionOffset = 5
+	blobHeaderFormatOffset  = 6
+	blobHeaderLengthOffset  = 7
+
+	blobHeaderCompressionOffset = 15
+)
+
+var blobCodecMagic = []byte("DILL")
+
+// BlobFormat is the layout of the payload in the field elements following
+// the blob header.
+type BlobFormat byte
+
+const (
+	BlobFormatPacked31  BlobFormat = 0x00
+	BlobFormatPacked254 BlobFormat = 0x01
+)
+
+func (f BlobFormat) String() string {
+	switch f {
+	case BlobFormatPacked31:
+		return "packed31"
+	case BlobFormatPacked254:
+		return "packed254"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(f))
+	}
+}
+
+// ParseBlobFormat parses the value of the packing flag.
+func ParseBlobFormat(name string) (BlobFormat, error) {
+	switch name {
+	case "", "packed31":
+		return BlobFormatPacked31, nil
+	case "packed254":
+		return BlobFormatPacked254, nil
+	default:
+		return BlobFormatPacked31, fmt.Errorf("unknown packing %q, expected packed31 or packed254", name)
+	}
+}
+
+// payloadSize returns the number of payload bytes a blob carries in format f.
+func (f BlobFormat) payloadSize() int {
+	if f == BlobFormatPacked254 {
+		return blobPayloadSize254
+	}
+	return blobPayloadSize
+}
+
+type blobHeader struct {
+	version     byte
+	format      BlobFormat
+	length      uint64
+	compression Compression
+}
+
+// encodeBlobs splits data over as many blobs as needed. It always returns at
+// least one blob, so empty data still yields a decodable (empty) blob. data
+// must already be compressed with compression, which is only recorded.
+func encodeBlobs(data []byte, format BlobFormat, compression Compression) []kzg4844.Blob {
+	blobs := []kzg4844.Blob{}
+	size := format.payloadSize()
+	for i := 0; i == 0 || i < len(data); i += size {
+		max := i + size
+		if max > len(data) {
+			max = len(data)
+		}
+		blobs = append(blobs, encodeBlob(data[i:max], format, compression))
+	}
+	return blobs
+}
+
+// blobsNeeded returns the number of blobs encodeBlobs produces for size bytes
+// in format f.
+func (f BlobFormat) blobsNeeded(size int64) int {
+	if size == 0 {
+		return 1
+	}
+	payload := int64(f.payloadSize())
+	return int((size + payload - 1) / payload)
+}
+
+// encodeBlob packs at most format.payloadSize() bytes of data into a single
+// blob.
+func encodeBlob(data []byte, format BlobFormat, compression Compression) kzg4844.Blob {
+	var blob kzg4844.Blob
+	writeBlobHeader(&blob, blobHeader{
+		version:     blobCodecVersion,
+		format:      format,
+		length:      uint64(len(data)),
+		compression: compression,
+	})
+	if format == BlobFormatPacked254 {
+		pack254(blob[fieldElementSize:], data)
+		return blob
+	}
+	fieldIndex := 1
+	for i := 0; i < len(data); i += 31 {
+		max := i + 31
+		if max > len(data) {
+			max = len(data)
+		}
+		copy(blob[fieldIndex*fieldElementSize+1:], data[i:max])
+		fieldIndex++
+	}
+	return blob
+}
+
+// pack254 writes data as a bit stream into the low 254 bits of consecutive
+// field elements of dst. Field element j holds stream bits [254j, 254j+254),
+// which is the 256 bits starting 2 bits earlier with the top 2 bits cleared.
+func pack254(dst []byte, data []byte) {
+	elements := (len(data)*8 + 253) / 254
+	for j := 0; j < elements; j++ {
+		elem := dst[j*fieldElementSize : (j+1)*fieldElementSize]
+		start := j*254 - 2
+		for k := range elem {
+			elem[k] = bitsAt(data, start+8*k)
+		}
+		elem[0] &= 0x3f
+	}
+}
+
+// unpack254 reverses pack254, returning length bytes read from src.
+func unpack254(src []byte, length int) ([]byte, error) {
+	data := make([]byte, length)
+	elements := (length*8 + 253) / 254
+	for j := 0; j < elements; j++ {
+		elem := src[j*fieldElementSize : (j+1)*fieldElementSize]
+		if elem[0]&0xc0 != 0 {
+			return nil, fmt.Errorf("field element %d uses more than 254 bits", j+1)
+		}
+		start := j*254 - 2
+		for k := range elem {
+			orBitsAt(data, start+8*k, elem[k])
+		}
+	}
+	return data, nil
+}
+
+// bitsAt returns the 8 bits of src starting at bit pos, most significant bit
+// first. Bits outside src read as zero, pos may be as low as -8.
+func bitsAt(src []byte, pos int) byte {
+	i := (pos+8)/8 - 1
+	s := pos - i*8
+	var hi, lo byte
+	if i >= 0 && i < len(src) {
+		hi = src[i]
+	}
+	if i+1 >= 0 && i+1 < len(src) {
+		lo = src[i+1]
+	}
+	if s == 0 {
+		return hi
+	}
+	return hi<<s | lo>>(8-s)
+}
+
+// orBitsAt sets the 8 bits of dst starting at bit pos from b, dropping bits
+// outside dst. It is the inverse of bitsAt.
+func orBitsAt(dst []byte, pos int, b byte) {
+	i := (pos+8)/8 - 1
+	s := pos - i*8
+	if i >= 0 && i < len(dst) {
+		dst[i] |= b >> s
+	}
+	if s != 0 && i+1 >= 0 && i+1 < len(dst) {
+		dst[i+1] |= b << (8 - s)
+	}
+}
+
+func writeBlobHeader(blob *kzg4844.Blob, h blobHeader) {
+	copy(blob[blobHeaderMagicOffset:], blobCodecMagic)
+	blob[blobHeaderVersionOffset] = h.version
+	blob[blobHeaderFormatOffset] = byte(h.format)
+	binary.BigEndian.PutUint64(blob[blobHeaderLengthOffset:], h.length)
+	blob[blobHeaderCompressionOffset] = byte(h.compression)
+}
+
+// readBlobHeader returns the header of blob, or false if the blob was not
+// produced by this codec.
+func readBlobHeader(blob []byte) (blobHeader, bool) {
+	if blob[0] != 0x00 || !bytes.Equal(blob[blobHeaderMagicOffset:blobHeaderVersionOffset], blobCodecMagic) {
+		return blobHeader{}, false
+	}
+	return blobHeader{
+		version:     blob[blobHeaderVersionOffset],
+		format:      BlobFormat(blob[blobHeaderFormatOffset]),
+		length:      binary.BigEndian.Uint64(blob[blobHeaderLengthOffset:]),
+		compression: Compression(blob[blobHeaderCompressionOffset]),
+	}, true
+}
+
+// DecodeBlob returns the payload carried by a single blob. Blobs without a
+// codec header are decoded with the legacy layout. The payload is returned
+// as stored, DecodeBlobs also undoes the payload compression.
+func DecodeBlob(blob []byte) ([]byte, error) {
+	data, _, err := decodeBlob(blob)
+	return data, err
+}
+
+func decodeBlob(blob []byte) ([]byte, blobHeader, error) {
+	if len(blob) != blobSize {
+		return nil, blobHeader{}, fmt.Errorf("invalid blob length %d, expected %d", len(blob), blobSize)
+	}
+	h, ok := readBlobHeader(blob)
+	if !ok {
+		return decodeLegacyBlob(blob), blobHeader{}, nil
+	}
+	if h.version != blobCodecVersion {
+		return nil, h, fmt.Errorf("unsupported blob codec version %d", h.version)
+	}
+	if h.format != BlobFormatPacked31 && h.format != BlobFormatPacked254 {
+		return nil, h, fmt.Errorf("unsupported blob format %d", h.format)
+	}
+	if h.length > uint64(h.format.payloadSize()) {
+		return nil, h, fmt.Errorf("invalid payload length %d, %v blob holds at most %d bytes", h.length, h.format, h.format.payloadSize())
+	}
+	if h.format == BlobFormatPacked254 {
+		data, err := unpack254(blob[fieldElementSize:], int(h.length))
+		return data, h, err
+	}
+	data := make([]byte, 0, h.length)
+	for j := fieldElementSize; uint64(len(data)) < h.length; j += fieldElementSize {
+		data = append(data, blob[j+1:j+fieldElementSize]...)
+	}
+	return data[:h.length], h, nil
+}
+
+// DecodeBlobs decodes blobs in order and returns the concatenated payload,
+// decompressed with the algorithm recorded in the blob headers.
+func DecodeBlobs(blobs [][]byte) ([]byte, error) {
+	if len(blobs) == 0 {
+		return nil, errors.New("no blobs to decode")
+	}
+	var (
+		data        []byte
+		compression Compression
+	)
+	for i, blob := range blobs {
+		d, h, err := decodeBlob(blob)
+		if err != nil {
+			return nil, fmt.Errorf("blob %d: %w", i, err)
+		}
+		if i == 0 {
+			compression = h.compression
+		} else if h.compression != compression {
+			return nil, fmt.Errorf("blob %d: compression %v does not match %v of blob 0", i, h.compression, compression)
+		}
+		data = append(data, d...)
+	}
+	data, err := decompressPayload(data, compression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %v payload: %w", compression, err)
+	}
+	return data, nil
+}
+
+// decodeLegacyBlob decodes blobs written before the codec header existed,
+// where every field element carries 31 bytes in bytes 0-30.
+func decodeLegacyBlob(blob []byte) []byte {
+	var data []byte
+
+	// XXX: the legacy layout has no length, so trailing 0s are removed, which could be unexpected for certain blobs
+	j := 0
+	for i := 0; i < params.BlobTxFieldElementsPerBlob; i++ {
+		data = append(data, blob[j:j+31]...)
+		j += 32
+	}
+
+	i := len(data) - 1
+	for ; i >= 0; i-- {
+		if data[i] != 0x00 {
+			break
+		}
+	}
+	data = data[:i+1]
+	return data
+}
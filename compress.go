@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression identifies the algorithm applied to the payload before it is
+// packed into blobs. It is recorded in every blob header.
+type Compression byte
+
+const (
+	CompressionNone   Compression = 0x00
+	CompressionZstd   Compression = 0x01
+	CompressionBrotli Compression = 0x02
+)
+
+func (c Compression) String() string {
+	switch c {
+	case CompressionNone:
+		return "none"
+	case CompressionZstd:
+		return "zstd"
+	case CompressionBrotli:
+		return "brotli"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(c))
+	}
+}
+
+// ParseCompression parses the value of the compression flag.
+func ParseCompression(name string) (Compression, error) {
+	switch name {
+	case "", "none":
+		return CompressionNone, nil
+	case "zstd":
+		return CompressionZstd, nil
+	case "brotli":
+		return CompressionBrotli, nil
+	default:
+		return CompressionNone, fmt.Errorf("unknown compression %q, expected none, zstd or brotli", name)
+	}
+}
+
+// newCompressWriter wraps w so that everything written is compressed with c.
+// Blob gas is the main cost of posting data, so the best ratio is preferred
+// over speed.
+func newCompressWriter(w io.Writer, c Compression) (io.WriteCloser, error) {
+	switch c {
+	case CompressionZstd:
+		return zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedBestCompression))
+	case CompressionBrotli:
+		return brotli.NewWriterLevel(w, brotli.BestCompression), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %v", c)
+	}
+}
+
+func compressPayload(data []byte, c Compression) ([]byte, error) {
+	if c == CompressionNone {
+		return data, nil
+	}
+	var buf bytes.Buffer
+	w, err := newCompressWriter(&buf, c)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// compressReader returns a reader yielding the data of r compressed with c.
+// Closing it stops the compressing goroutine, even before r is drained.
+func compressReader(r io.Reader, c Compression) (io.ReadCloser, error) {
+	if c == CompressionNone {
+		return io.NopCloser(r), nil
+	}
+	pr, pw := io.Pipe()
+	w, err := newCompressWriter(pw, c)
+	if err != nil {
+		return nil, err
+	}
+	go func() {
+		_, err := io.Copy(w, r)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr, nil
+}
+
+func decompressPayload(data []byte, c Compression) ([]byte, error) {
+	switch c {
+	case CompressionNone:
+		return data, nil
+	case CompressionZstd:
+		d, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		defer d.Close()
+		return d.DecodeAll(data, nil)
+	case CompressionBrotli:
+		return io.ReadAll(brotli.NewReader(bytes.NewReader(data)))
+	default:
+		return nil, fmt.Errorf("unsupported compression %v", c)
+	}
+}
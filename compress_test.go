@@ -0,0 +1,57 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompressPayload(t *testing.T) {
+	data := bytes.Repeat([]byte("dill blob compression "), 1000)
+	for _, c := range []Compression{CompressionNone, CompressionZstd, CompressionBrotli} {
+		t.Run(c.String(), func(t *testing.T) {
+			compressed, err := compressPayload(data, c)
+			require.NoError(t, err)
+			if c != CompressionNone {
+				require.Less(t, len(compressed), len(data))
+			}
+
+			blobs := encodeBlobs(compressed, BlobFormatPacked31, c)
+			dec, err := DecodeBlobs(blobsToBytes(blobs))
+			require.NoError(t, err)
+			require.Equal(t, data, dec)
+		})
+	}
+}
+
+func TestParseCompression(t *testing.T) {
+	for _, c := range []Compression{CompressionNone, CompressionZstd, CompressionBrotli} {
+		parsed, err := ParseCompression(c.String())
+		require.NoError(t, err)
+		require.Equal(t, c, parsed)
+	}
+	_, err := ParseCompression("gzip")
+	require.Error(t, err)
+}
+
+func TestCompressReaderClose(t *testing.T) {
+	for _, c := range []Compression{CompressionZstd, CompressionBrotli} {
+		before := runtime.NumGoroutine()
+		r, err := compressReader(rand.Reader, c)
+		require.NoError(t, err)
+		_, err = io.ReadFull(r, make([]byte, 1024))
+		require.NoError(t, err)
+		// the endless input is never drained, closing must stop the compression
+		require.NoError(t, r.Close())
+		deadline := time.Now().Add(5 * time.Second)
+		for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		require.LessOrEqual(t, runtime.NumGoroutine(), before, c.String())
+	}
+}
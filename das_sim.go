@@ -0,0 +1,240 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"os"
+	"text/tabwriter"
+
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/urfave/cli"
+)
+
+// dasSimConfig parameterizes simulateDAS.
+type dasSimConfig struct {
+	// Nodes is the number of simulated DAS nodes.
+	Nodes int
+	// Replication is the number of nodes every segment is stored on.
+	Replication int
+	// WithholdRate is the fraction of the segments of every blob the
+	// publisher never hands out.
+	WithholdRate float64
+	// FailureRate is the fraction of nodes that are offline.
+	FailureRate float64
+	// CorruptRate is the fraction of nodes that serve corrupted segment
+	// proofs.
+	CorruptRate float64
+	// RecoveryThreshold is the fraction of available segments at which a
+	// blob counts as recoverable. The simulation does not erasure decode, it
+	// only compares the count against this threshold.
+	RecoveryThreshold float64
+	// Clients is the number of sampling light clients.
+	Clients int
+	// MaxSamples is the largest number of samples a client takes.
+	MaxSamples int
+}
+
+// simNode is a simulated DAS node holding segment proofs by blob and segment
+// index. Corrupt nodes hold altered copies of the proofs.
+type simNode struct {
+	online   bool
+	corrupt  bool
+	segments map[[2]int]kzg4844.Proof
+}
+
+// dasSimResult summarizes a simulation. A blob is above the threshold if at
+// least cfg.RecoveryThreshold of its segments are available with a matching
+// proof. Detections[k-1] and FalseAlerts[k-1] count the clients that saw at
+// least one unavailable segment within their first k samples of a blob below
+// and above the threshold respectively.
+type dasSimResult struct {
+	Available      []int
+	Segments       []int
+	AboveThreshold []bool
+
+	AboveThresholdRuns int
+	BelowThresholdRuns int
+	FalseAlerts        []int
+	Detections         []int
+}
+
+// simulateDAS spreads the segment proofs of bundle over cfg.Nodes nodes and
+// lets cfg.Clients light clients sample them. A sample only succeeds if an
+// online node serves a proof equal to the one stored in bundle for the
+// sampled segment, proofs served by corrupt nodes are rejected.
+func simulateDAS(bundle *BlobBundle, cfg dasSimConfig, rng *rand.Rand) (*dasSimResult, error) {
+	if cfg.Nodes < 1 || cfg.Replication < 1 || cfg.Replication > cfg.Nodes {
+		return nil, fmt.Errorf("invalid replication %d over %d nodes", cfg.Replication, cfg.Nodes)
+	}
+	for _, rate := range []float64{cfg.WithholdRate, cfg.FailureRate, cfg.CorruptRate} {
+		if rate < 0 || rate > 1 {
+			return nil, errors.New("withhold, failure and corrupt rates must be between 0 and 1")
+		}
+	}
+	nodes := make([]simNode, cfg.Nodes)
+	for i := range nodes {
+		nodes[i] = simNode{
+			online:   rng.Float64() >= cfg.FailureRate,
+			corrupt:  rng.Float64() < cfg.CorruptRate,
+			segments: make(map[[2]int]kzg4844.Proof),
+		}
+	}
+
+	res := &dasSimResult{
+		Available:      make([]int, bundle.Len()),
+		Segments:       make([]int, bundle.Len()),
+		AboveThreshold: make([]bool, bundle.Len()),
+		FalseAlerts:    make([]int, cfg.MaxSamples),
+		Detections:     make([]int, cfg.MaxSamples),
+	}
+	holders := make([][][]int, bundle.Len())
+	for b, proofs := range bundle.SegmentProofs() {
+		res.Segments[b] = len(proofs)
+		if len(proofs) == 0 {
+			return nil, fmt.Errorf("blob %d has no segment proofs", b)
+		}
+		withheld := make(map[int]bool)
+		for _, s := range rng.Perm(len(proofs))[:int(math.Round(cfg.WithholdRate*float64(len(proofs))))] {
+			withheld[s] = true
+		}
+		holders[b] = make([][]int, len(proofs))
+		for s, proof := range proofs {
+			holders[b][s] = rng.Perm(cfg.Nodes)[:cfg.Replication]
+			if withheld[s] {
+				continue
+			}
+			for _, n := range holders[b][s] {
+				served := proof
+				if nodes[n].corrupt {
+					served[len(served)-1] ^= 0x01
+				}
+				nodes[n].segments[[2]int{b, s}] = served
+			}
+		}
+		for s := range proofs {
+			if serveSegment(nodes, holders[b][s], b, s, proofs[s]) {
+				res.Available[b]++
+			}
+		}
+		res.AboveThreshold[b] = float64(res.Available[b]) >= cfg.RecoveryThreshold*float64(len(proofs))
+	}
+
+	for c := 0; c < cfg.Clients; c++ {
+		b := rng.Intn(bundle.Len())
+		proofs := bundle.SegmentProofs()[b]
+		// the client alerts from the first failed sample on
+		first := cfg.MaxSamples
+		for k, s := range rng.Perm(len(proofs)) {
+			if k == cfg.MaxSamples {
+				break
+			}
+			if !serveSegment(nodes, holders[b][s], b, s, proofs[s]) {
+				first = k
+				break
+			}
+		}
+		alerts := res.Detections
+		if res.AboveThreshold[b] {
+			res.AboveThresholdRuns++
+			alerts = res.FalseAlerts
+		} else {
+			res.BelowThresholdRuns++
+		}
+		for k := first; k < cfg.MaxSamples; k++ {
+			alerts[k]++
+		}
+	}
+	return res, nil
+}
+
+// serveSegment reports whether any online holder serves a proof of segment s
+// of blob b equal to expected, the proof stored in the bundle. The proof is
+// compared, not verified, since there is no segment proof verification to
+// check it against the commitment with.
+func serveSegment(nodes []simNode, holders []int, b, s int, expected kzg4844.Proof) bool {
+	for _, n := range holders {
+		if !nodes[n].online {
+			continue
+		}
+		if proof, ok := nodes[n].segments[[2]int{b, s}]; ok && proof == expected {
+			return true
+		}
+	}
+	return false
+}
+
+func DasSimApp(cliCtx *cli.Context) error {
+	input := cliCtx.String(DasSimInputFlag.Name)
+	blobCnt := cliCtx.Uint64(TxBlobCountFlag.Name)
+	parallelism := cliCtx.Int(EncodeParallelismFlag.Name)
+	seed := cliCtx.Int64(DasSimSeedFlag.Name)
+	cfg := dasSimConfig{
+		Nodes:             cliCtx.Int(DasSimNodesFlag.Name),
+		Replication:       cliCtx.Int(DasSimReplicationFlag.Name),
+		WithholdRate:      cliCtx.Float64(DasSimWithholdRateFlag.Name),
+		FailureRate:       cliCtx.Float64(DasSimFailureRateFlag.Name),
+		CorruptRate:       cliCtx.Float64(DasSimCorruptRateFlag.Name),
+		RecoveryThreshold: cliCtx.Float64(DasSimRecoveryThresholdFlag.Name),
+		Clients:           cliCtx.Int(DasSimClientsFlag.Name),
+		MaxSamples:        cliCtx.Int(DasSimMaxSamplesFlag.Name),
+	}
+	if cfg.Clients < 1 || cfg.MaxSamples < 1 {
+		return errors.New("clients and max-samples must be positive")
+	}
+
+	var bundle *BlobBundle
+	if input == "" {
+		bundle = randomBlobs(int(blobCnt), EncodeOptions{Parallelism: parallelism, SegmentProofs: true})
+	} else {
+		r, err := openInput(input)
+		if err != nil {
+			return fmt.Errorf("error reading input file: %v", err)
+		}
+		defer r.Close()
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("error reading input file: %v", err)
+		}
+		opts := DefaultEncodeOptions()
+		opts.Parallelism = parallelism
+		opts.SegmentProofs = true
+		if bundle, err = EncodeBlobsWithOptions(data, opts); err != nil {
+			return err
+		}
+	}
+
+	res, err := simulateDAS(bundle, cfg, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return err
+	}
+	for b := range res.Segments {
+		threshold := "below"
+		if res.AboveThreshold[b] {
+			threshold = "at or above"
+		}
+		log.Printf("blob %d: %d of %d segments available, %s the %v recovery threshold", b, res.Available[b], res.Segments[b], threshold, cfg.RecoveryThreshold)
+	}
+	log.Printf("%d clients sampled blobs below the recovery threshold, %d at or above it", res.BelowThresholdRuns, res.AboveThresholdRuns)
+	log.Printf("reconstruction is not simulated, the recovery threshold only counts available segments")
+
+	// detection is the alert rate on blobs below the recovery threshold,
+	// false alerts the rate on blobs above it
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "samples\tdetection\tfalse alerts")
+	for k := 0; k < cfg.MaxSamples; k++ {
+		fmt.Fprintf(w, "%d\t%s\t%s\n", k+1, dasSimRate(res.Detections[k], res.BelowThresholdRuns), dasSimRate(res.FalseAlerts[k], res.AboveThresholdRuns))
+	}
+	return w.Flush()
+}
+
+// dasSimRate formats n of runs as a probability, or n/a without runs.
+func dasSimRate(n, runs int) string {
+	if runs == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.4f", float64(n)/float64(runs))
+}
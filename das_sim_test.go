@@ -0,0 +1,48 @@
+package main
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulateDAS(t *testing.T) {
+	bundle, err := computeBlobBundle(encodeBlobs(makeBlob(2*blobPayloadSize), BlobFormatPacked31, CompressionNone), EncodeOptions{Parallelism: 2, SegmentProofs: true})
+	require.NoError(t, err)
+	cfg := dasSimConfig{Nodes: 16, Replication: 2, RecoveryThreshold: 0.5, Clients: 200, MaxSamples: 8}
+
+	res, err := simulateDAS(bundle, cfg, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	for b := range res.Segments {
+		require.Equal(t, res.Segments[b], res.Available[b])
+		require.True(t, res.AboveThreshold[b])
+	}
+	require.Equal(t, cfg.Clients, res.AboveThresholdRuns)
+	require.Equal(t, make([]int, cfg.MaxSamples), res.FalseAlerts)
+
+	cfg.WithholdRate = 0.75
+	res, err = simulateDAS(bundle, cfg, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	for b := range res.Segments {
+		require.False(t, res.AboveThreshold[b])
+	}
+	require.Equal(t, cfg.Clients, res.BelowThresholdRuns)
+	for k := 1; k < cfg.MaxSamples; k++ {
+		require.GreaterOrEqual(t, res.Detections[k], res.Detections[k-1])
+	}
+	require.Greater(t, res.Detections[cfg.MaxSamples-1], cfg.Clients*9/10)
+
+	cfg.WithholdRate = 1
+	res, err = simulateDAS(bundle, cfg, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	require.Equal(t, cfg.Clients, res.Detections[0])
+
+	// every node holds the segments, but serves proofs not matching the bundle
+	cfg.WithholdRate = 0
+	cfg.CorruptRate = 1
+	res, err = simulateDAS(bundle, cfg, rand.New(rand.NewSource(1)))
+	require.NoError(t, err)
+	require.Equal(t, make([]int, bundle.Len()), res.Available)
+	require.Equal(t, cfg.Clients, res.Detections[0])
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/urfave/cli"
+)
+
+func DecodeApp(cliCtx *cli.Context) error {
+	output := cliCtx.String(DecodeOutputFlag.Name)
+	files := cliCtx.Args()
+	if len(files) == 0 {
+		return errors.New("no blob files given")
+	}
+
+	blobs := make([][]byte, 0, len(files))
+	for _, file := range files {
+		blob, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("error reading blob file: %v", err)
+		}
+		blobs = append(blobs, blob)
+	}
+	data, err := DecodeBlobs(blobs)
+	if err != nil {
+		return fmt.Errorf("failed to decode blobs: %v", err)
+	}
+
+	if output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(output, data, 0644); err != nil {
+		return fmt.Errorf("error writing output file: %v", err)
+	}
+	log.Printf("decoded %d blobs into %d bytes", len(blobs), len(data))
+	return nil
+}
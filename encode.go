@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/urfave/cli"
+)
+
+func EncodeApp(cliCtx *cli.Context) error {
+	input := cliCtx.String(EncodeInputFlag.Name)
+	outputDir := cliCtx.String(EncodeOutputDirFlag.Name)
+	withSidecar := cliCtx.Bool(EncodeSidecarFlag.Name)
+	withSegmentProofs := cliCtx.Bool(EncodeSegmentProofsFlag.Name)
+	parallelism := cliCtx.Int(EncodeParallelismFlag.Name)
+	logTimings := cliCtx.Bool(EncodeLogTimingsFlag.Name)
+	compression, err := ParseCompression(cliCtx.String(EncodeCompressionFlag.Name))
+	if err != nil {
+		return err
+	}
+	format, err := ParseBlobFormat(cliCtx.String(EncodePackingFlag.Name))
+	if err != nil {
+		return err
+	}
+	cache, err := bundleCacheFromFlags(cliCtx)
+	if err != nil {
+		return err
+	}
+
+	r, err := openInput(input)
+	if err != nil {
+		return fmt.Errorf("error reading input file: %v", err)
+	}
+	defer r.Close()
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("error creating output dir: %v", err)
+	}
+
+	var (
+		blobFiles []string
+		sidecar   sidecarJSON
+	)
+	stream, err := NewBlobStream(r, EncodeOptions{Parallelism: parallelism, Compression: compression, Format: format, SegmentProofs: withSegmentProofs, Cache: cache, LogTimings: logTimings})
+	if err != nil {
+		return err
+	}
+	defer stream.Close()
+	// a group of blobs per worker, so all of them have a blob to work on
+	group := max(parallelism, 1)
+	for {
+		var (
+			blobs  []kzg4844.Blob
+			bundle *BlobBundle
+		)
+		if withSidecar {
+			bundle, err = stream.Next(group)
+			if err == nil {
+				blobs = bundle.Blobs
+			}
+		} else {
+			blobs, err = stream.NextBlobs(group)
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to encode blob %d: %v", len(blobFiles), err)
+		}
+
+		first := len(blobFiles)
+		for j := range blobs {
+			i := len(blobFiles)
+			blobFile := fmt.Sprintf("blob-%04d.bin", i)
+			if err := os.WriteFile(filepath.Join(outputDir, blobFile), blobs[j][:], 0644); err != nil {
+				return fmt.Errorf("error writing blob %d: %v", i, err)
+			}
+			blobFiles = append(blobFiles, blobFile)
+			log.Printf("blob %d: %s (%v)", i, blobFile, format)
+		}
+		// the sidecar keeps the KZG data only, the blobs are in their files
+		if withSidecar {
+			sidecar.add(blobFiles[first:], format, bundle, withSegmentProofs)
+		}
+	}
+	if withSidecar {
+		if err := writeSidecarJSON(filepath.Join(outputDir, "sidecar.json"), &sidecar); err != nil {
+			return fmt.Errorf("error writing sidecar: %v", err)
+		}
+	}
+	if compression != CompressionNone {
+		log.Printf("%v compressed %d bytes into %d bytes", compression, stream.Size(), stream.PayloadSize())
+	}
+	log.Printf("encoded %d bytes into %d %v blobs in %s", stream.Size(), stream.Blobs(), format, outputDir)
+	return nil
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/DillLabs/dill-blob-utils/hex"
+)
+
+// pointEvaluationFixturesJSON is the Foundry fixture file. Foundry decodes
+// JSON objects into structs with their keys in alphabetical order, so the
+// fields of pointEvaluationFixtureJSON are declared sorted.
+type pointEvaluationFixturesJSON struct {
+	Valid   []pointEvaluationFixtureJSON `json:"valid"`
+	Invalid []pointEvaluationFixtureJSON `json:"invalid"`
+}
+
+type pointEvaluationFixtureJSON struct {
+	Commitment    string `json:"commitment"`
+	Input         string `json:"input"`
+	Name          string `json:"name"`
+	Proof         string `json:"proof"`
+	VersionedHash string `json:"versionedHash"`
+	Y             string `json:"y"`
+	Z             string `json:"z"`
+}
+
+// pointEvaluationCorruptions derive the invalid fixtures, every one of them
+// is rejected by the point evaluation precompile.
+var pointEvaluationCorruptions = []struct {
+	name    string
+	corrupt func(e *pointEvaluation)
+}{
+	{"wrong_y", func(e *pointEvaluation) { e.Y[len(e.Y)-1] ^= 0x01 }},
+	{"wrong_z", func(e *pointEvaluation) { e.Z[len(e.Z)-1] ^= 0x01 }},
+	{"wrong_proof", func(e *pointEvaluation) { copy(e.Proof[:], e.Commitment[:]) }},
+	{"wrong_versioned_hash", func(e *pointEvaluation) { e.VersionedHash[len(e.VersionedHash)-1] ^= 0x01 }},
+	{"non_canonical_z", func(e *pointEvaluation) { copy(e.Z[:], blsModulus) }},
+}
+
+var pointEvaluationFixturesSol = template.Must(template.New("sol").Parse(`// SPDX-License-Identifier: UNLICENSED
+// Code generated by dill-blob-utils proof. DO NOT EDIT.
+pragma solidity ^0.8.0;
+
+library PointEvaluationFixtures {
+	uint256 internal constant VALID_COUNT = {{len .Valid}};
+	uint256 internal constant INVALID_COUNT = {{len .Invalid}};
+{{range .Fixtures}}
+	// {{.Name}}
+	bytes32 internal constant {{.Const}}_VERSIONED_HASH = {{.VersionedHash}};
+	bytes32 internal constant {{.Const}}_Z = {{.Z}};
+	bytes32 internal constant {{.Const}}_Y = {{.Y}};
+	bytes internal constant {{.Const}}_COMMITMENT = hex"{{.Commitment}}";
+	bytes internal constant {{.Const}}_PROOF = hex"{{.Proof}}";
+	bytes internal constant {{.Const}}_INPUT = hex"{{.Input}}";
+{{end -}}
+}
+`))
+
+// writePointEvaluationFixtures writes point_evaluations.json for Foundry's
+// vm.parseJson and PointEvaluationFixtures.sol with the same valid and
+// corrupted cases as constants.
+func writePointEvaluationFixtures(dir string, evals []pointEvaluation) error {
+	var fixtures pointEvaluationFixturesJSON
+	for i := range evals {
+		name := fmt.Sprintf("valid_%d", i)
+		fixtures.Valid = append(fixtures.Valid, newPointEvaluationFixture(name, &evals[i]))
+		for _, c := range pointEvaluationCorruptions {
+			e := evals[i]
+			c.corrupt(&e)
+			fixtures.Invalid = append(fixtures.Invalid, newPointEvaluationFixture(fmt.Sprintf("%s_%d", c.name, i), &e))
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(fixtures, "", "\t")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(dir, "point_evaluations.json"), b, 0644); err != nil {
+		return err
+	}
+
+	type solFixture struct {
+		pointEvaluationFixtureJSON
+		Const string
+	}
+	data := struct {
+		pointEvaluationFixturesJSON
+		Fixtures []solFixture
+	}{pointEvaluationFixturesJSON: fixtures}
+	for _, f := range append(fixtures.Valid, fixtures.Invalid...) {
+		// hex"" literals take the digits without 0x
+		f.Commitment = strings.TrimPrefix(f.Commitment, "0x")
+		f.Proof = strings.TrimPrefix(f.Proof, "0x")
+		f.Input = strings.TrimPrefix(f.Input, "0x")
+		data.Fixtures = append(data.Fixtures, solFixture{f, strings.ToUpper(f.Name)})
+	}
+	var sol bytes.Buffer
+	if err := pointEvaluationFixturesSol.Execute(&sol, data); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, "PointEvaluationFixtures.sol"), sol.Bytes(), 0644)
+}
+
+func newPointEvaluationFixture(name string, e *pointEvaluation) pointEvaluationFixtureJSON {
+	return pointEvaluationFixtureJSON{
+		Commitment:    hex.EncodeToHex(e.Commitment[:]),
+		Input:         hex.EncodeToHex(e.input()),
+		Name:          name,
+		Proof:         hex.EncodeToHex(e.Proof[:]),
+		VersionedHash: e.VersionedHash.Hex(),
+		Y:             hex.EncodeToHex(e.Y[:]),
+		Z:             hex.EncodeToHex(e.Z[:]),
+	}
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"runtime"
+	"strconv"
+
 	"github.com/urfave/cli"
 )
 
@@ -12,7 +15,7 @@ var (
 	}
 	TxBlobFileFlag = cli.StringFlag{
 		Name:  "blob-file",
-		Usage: "Blob file data",
+		Usage: "Blob file data, - reads from stdin",
 	}
 	TxBlobSizeFlag = cli.Uint64Flag{
 		Name:  "blob-size",
@@ -92,19 +95,200 @@ var (
 
 	ProofBlobFileFlag = cli.StringFlag{
 		Name:     "blob-file",
-		Usage:    "Blob file data",
+		Usage:    "Blob file data, - reads from stdin",
 		Required: true,
 	}
-	ProofBlobIndexFlag = cli.StringFlag{
-		Name:     "blob-index",
-		Usage:    "Blob index",
-		Required: true,
+	ProofBlobIndexFlag = cli.Int64SliceFlag{
+		Name:  "blob-index",
+		Usage: "Blob index, the index field of the entry for sidecar input, repeat for several blobs (default 0)",
+	}
+	ProofInputFormatFlag = cli.StringFlag{
+		Name:  "input-format",
+		Usage: "Format of the blob file: data (encoded into blobs), blob (raw canonical blobs), hex (hex encoded raw blobs) or sidecar (sidecar JSON or beacon API blob_sidecars response)",
+		Value: "data",
+	}
+	ProofInputPointFlag = cli.StringSliceFlag{
+		Name:  "input-point",
+		Usage: "Input point of the proof as 0x prefixed hex, 64 hex digits, decimal or hash:<string>, repeat for several points",
+	}
+	ProofReducePointFlag = cli.BoolFlag{
+		Name:  "reduce-point",
+		Usage: "Reduce input points modulo the BLS modulus instead of rejecting them",
+	}
+	ProofChallengePointFlag = cli.BoolFlag{
+		Name:  "challenge-point",
+		Usage: "Also evaluate every blob at the Fiat-Shamir challenge used by its blob proof",
+	}
+	ProofRandomPointsFlag = cli.IntFlag{
+		Name:  "random-points",
+		Usage: "Number of random input points to evaluate in addition to input-point",
+	}
+	ProofPointSeedFlag = cli.StringFlag{
+		Name:  "point-seed",
+		Usage: "Derive the random-points from this seed, so every run uses the same points",
+	}
+	ProofOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "File the point evaluations are written to as JSON, stdout if empty",
+	}
+	ProofFixturesDirFlag = cli.StringFlag{
+		Name:  "fixtures-dir",
+		Usage: "Directory Foundry fixtures of the point evaluations are written to, valid and corrupted cases as point_evaluations.json and PointEvaluationFixtures.sol",
 	}
-	ProofInputPointFlag = cli.StringFlag{
-		Name:     "input-point",
-		Usage:    "Input point of the proof",
+
+	VerifyPointInputFlag = cli.StringFlag{
+		Name:  "input",
+		Usage: "JSON point evaluations written by the proof command, - reads from stdin",
+	}
+	VerifyPointLocalFlag = cli.BoolFlag{
+		Name:  "local",
+		Usage: "Only verify with the local KZG setup, without calling the precompile",
+	}
+	VerifyPointEvalInputFlag = cli.StringSliceFlag{
+		Name:  "point-eval-input",
+		Usage: "Hex encoded 192 byte point evaluation precompile input, repeat for several inputs",
+	}
+
+	EncodeInputFlag = cli.StringFlag{
+		Name:     "input",
+		Usage:    "File to encode into blobs, - reads from stdin",
 		Required: true,
 	}
+	EncodeOutputDirFlag = cli.StringFlag{
+		Name:  "output-dir",
+		Usage: "Directory the blob files are written to",
+		Value: "blobs",
+	}
+	EncodeSidecarFlag = cli.BoolFlag{
+		Name:  "sidecar",
+		Usage: "Also write commitments, proofs and versioned hashes to sidecar.json",
+	}
+	EncodeSegmentProofsFlag = cli.BoolFlag{
+		Name:  "segment-proofs",
+		Usage: "Also write the dill-das segment proofs to sidecar.json",
+	}
+	EncodeParallelismFlag = cli.IntFlag{
+		Name:  "parallelism",
+		Usage: "Number of workers computing blob commitments and proofs",
+		Value: runtime.NumCPU(),
+	}
+	EncodeLogTimingsFlag = cli.BoolFlag{
+		Name:  "verbose",
+		Usage: "Log the time spent computing commitments, blob proofs and segment proofs",
+	}
+	EncodeCompressionFlag = cli.StringFlag{
+		Name:  "compression",
+		Usage: "Compress the data before packing it into blobs: none, zstd or brotli",
+		Value: "none",
+	}
+	EncodePackingFlag = cli.StringFlag{
+		Name:  "packing",
+		Usage: "Field element packing: packed31 (31 bytes per element) or packed254 (all 254 usable bits, fewer blobs)",
+		Value: "packed31",
+	}
+	CacheDirFlag = cli.StringFlag{
+		Name:  "cache-dir",
+		Usage: "Directory caching computed commitments and proofs, defaults to the user cache directory",
+	}
+	NoCacheFlag = cli.BoolFlag{
+		Name:  "no-cache",
+		Usage: "Always compute commitments and proofs instead of using the cache",
+	}
+	ClearCacheFlag = cli.BoolFlag{
+		Name:  "clear-cache",
+		Usage: "Remove all cached commitments and proofs from the cache directory before running",
+	}
+	CacheMaxSizeFlag = cli.Int64Flag{
+		Name:  "cache-max-size",
+		Usage: "Size in MiB the cache is kept below by removing the least recently used entries, 0 for no limit",
+		Value: DefaultBundleCacheSize >> 20,
+	}
+	DecodeOutputFlag = cli.StringFlag{
+		Name:  "output",
+		Usage: "File the decoded data is written to, stdout if empty",
+	}
+	SegmentsOutputDirFlag = cli.StringFlag{
+		Name:  "output-dir",
+		Usage: "Directory the segment files are written to",
+		Value: "segments",
+	}
+	SegmentsFormatFlag = cli.StringFlag{
+		Name:  "format",
+		Usage: "Segment file format: json (indices and proofs) or binary (proofs back to back)",
+		Value: "json",
+	}
+	VerifySegmentsFlag = cli.BoolFlag{
+		Name:  "segments",
+		Usage: "Also recompute the dill-das segment proofs and compare them with the sidecar",
+	}
+
+	DasSimInputFlag = cli.StringFlag{
+		Name:  "input",
+		Usage: "File whose blobs are simulated, random blobs if empty, - reads from stdin",
+	}
+	DasSimNodesFlag = cli.IntFlag{
+		Name:  "nodes",
+		Usage: "Number of simulated DAS nodes",
+		Value: 64,
+	}
+	DasSimReplicationFlag = cli.IntFlag{
+		Name:  "replication",
+		Usage: "Number of nodes storing every segment",
+		Value: 2,
+	}
+	DasSimWithholdRateFlag = cli.Float64Flag{
+		Name:  "withhold-rate",
+		Usage: "Fraction of the segments of every blob that are withheld",
+	}
+	DasSimFailureRateFlag = cli.Float64Flag{
+		Name:  "failure-rate",
+		Usage: "Fraction of nodes that are offline",
+	}
+	DasSimCorruptRateFlag = cli.Float64Flag{
+		Name:  "corrupt-rate",
+		Usage: "Fraction of nodes that serve corrupted segment proofs, caught by comparison with the computed proofs, not by verification",
+	}
+	DasSimRecoveryThresholdFlag = cli.Float64Flag{
+		Name:  "recovery-threshold",
+		Usage: "Fraction of available segments at which a blob counts as recoverable, no erasure decoding is simulated",
+		Value: 0.5,
+	}
+	DasSimClientsFlag = cli.IntFlag{
+		Name:  "clients",
+		Usage: "Number of sampling light clients",
+		Value: 1000,
+	}
+	DasSimMaxSamplesFlag = cli.IntFlag{
+		Name:  "max-samples",
+		Usage: "Report detection for 1 up to this many samples per client",
+		Value: 16,
+	}
+	DasSimSeedFlag = cli.Int64Flag{
+		Name:  "seed",
+		Usage: "Random seed of the simulation",
+		Value: 1,
+	}
+
+	BenchBlobCountsFlag = cli.StringFlag{
+		Name:  "blobs",
+		Usage: "Comma separated blob counts to benchmark",
+		Value: "1,6",
+	}
+	BenchParallelismFlag = cli.StringFlag{
+		Name:  "parallelism",
+		Usage: "Comma separated worker counts to benchmark",
+		Value: "1," + strconv.Itoa(runtime.NumCPU()),
+	}
+	BenchIterationsFlag = cli.IntFlag{
+		Name:  "iterations",
+		Usage: "Number of times every operation runs over all blobs",
+		Value: 3,
+	}
+	BenchJSONFlag = cli.StringFlag{
+		Name:  "json",
+		Usage: "File the results are also written to as JSON",
+	}
+
 	TxRPCURLSFlag = cli.StringSliceFlag{
 		Name:  "rpc-urls",
 		Usage: "Addresses of execution node JSON-RPC endpoint",
@@ -130,6 +314,31 @@ var (
 		Usage: "blob counts in a single tx",
 		Value: 2,
 	}
+	TxMaxBlobsPerTxFlag = cli.Uint64Flag{
+		Name:  "max-blobs-per-tx",
+		Usage: "max blobs in a single tx, larger blob files are sent in several txs",
+		Value: 6,
+	}
+	TxCanonicalFlag = cli.BoolFlag{
+		Name:  "canonical",
+		Usage: "blob-file holds raw 131072-byte blobs which are sent as is",
+	}
+	TxPadBlobFlag = cli.BoolFlag{
+		Name:  "pad-blob",
+		Usage: "zero pad a trailing partial blob of a canonical blob-file instead of failing",
+	}
+	TxOfflineFlag = cli.BoolFlag{
+		Name:  "offline",
+		Usage: "sign without dialing a node and write the raw txs instead of sending them, requires nonce, gas-price, priority-gas-price, max-fee-per-blob-gas and chain-id",
+	}
+	TxRawOutputFlag = cli.StringFlag{
+		Name:  "raw-tx-output",
+		Usage: "file the signed raw txs of offline are written to, one hex encoded tx with sidecar per line, stdout if empty",
+	}
+	SendRawTxFileFlag = cli.StringSliceFlag{
+		Name:  "raw-tx-file",
+		Usage: "Signed raw tx file, hex with one tx per line or binary, or a directory of .hex, .txt, .raw, .rlp and .bin files, - reads from stdin. Can be repeated",
+	}
 	TxBlobWaitInclusionFlag = cli.BoolTFlag{
 		Name:  "tx-wait-inclusion",
 		Usage: "if wait for tx inclusion",
@@ -150,6 +359,25 @@ var TxFlags = []cli.Flag{
 	TxChainID,
 	TxCalldata,
 	TxBlobCountFlag,
+	TxMaxBlobsPerTxFlag,
+	TxCanonicalFlag,
+	TxPadBlobFlag,
+	TxOfflineFlag,
+	TxRawOutputFlag,
+	EncodeParallelismFlag,
+	EncodeLogTimingsFlag,
+	EncodeCompressionFlag,
+	EncodePackingFlag,
+	CacheDirFlag,
+	NoCacheFlag,
+	ClearCacheFlag,
+	CacheMaxSizeFlag,
+}
+
+var SendRawFlags = []cli.Flag{
+	TxRPCURLFlag,
+	SendRawTxFileFlag,
+	TxBlobWaitInclusionFlag,
 }
 
 var StressBlobTxFlags = []cli.Flag{
@@ -169,6 +397,8 @@ var StressBlobTxFlags = []cli.Flag{
 	TxChainID,
 	TxCalldata,
 	TxSleepSuccessFlag,
+	EncodeParallelismFlag,
+	EncodeLogTimingsFlag,
 }
 
 var TransferTxFlags = []cli.Flag{
@@ -199,5 +429,81 @@ var DownloadFlags = []cli.Flag{
 var ProofFlags = []cli.Flag{
 	ProofBlobFileFlag,
 	ProofBlobIndexFlag,
+	ProofInputFormatFlag,
 	ProofInputPointFlag,
+	ProofReducePointFlag,
+	ProofChallengePointFlag,
+	ProofRandomPointsFlag,
+	ProofPointSeedFlag,
+	ProofOutputFlag,
+	ProofFixturesDirFlag,
+	EncodeParallelismFlag,
+	EncodeCompressionFlag,
+	EncodePackingFlag,
+	CacheDirFlag,
+	NoCacheFlag,
+	ClearCacheFlag,
+	CacheMaxSizeFlag,
+}
+
+var VerifyPointFlags = []cli.Flag{
+	TxRPCURLFlag,
+	VerifyPointInputFlag,
+	VerifyPointEvalInputFlag,
+	VerifyPointLocalFlag,
+}
+
+var EncodeFlags = []cli.Flag{
+	EncodeInputFlag,
+	EncodeOutputDirFlag,
+	EncodeSidecarFlag,
+	EncodeSegmentProofsFlag,
+	EncodeParallelismFlag,
+	EncodeLogTimingsFlag,
+	EncodeCompressionFlag,
+	EncodePackingFlag,
+	CacheDirFlag,
+	NoCacheFlag,
+	ClearCacheFlag,
+	CacheMaxSizeFlag,
+}
+
+var DecodeFlags = []cli.Flag{
+	DecodeOutputFlag,
+}
+
+var SegmentsFlags = []cli.Flag{
+	SegmentsOutputDirFlag,
+	SegmentsFormatFlag,
+	EncodeParallelismFlag,
+	CacheDirFlag,
+	NoCacheFlag,
+	ClearCacheFlag,
+	CacheMaxSizeFlag,
+}
+
+var VerifyFlags = []cli.Flag{
+	VerifySegmentsFlag,
+}
+
+var DasSimFlags = []cli.Flag{
+	DasSimInputFlag,
+	TxBlobCountFlag,
+	DasSimNodesFlag,
+	DasSimReplicationFlag,
+	DasSimWithholdRateFlag,
+	DasSimFailureRateFlag,
+	DasSimCorruptRateFlag,
+	DasSimRecoveryThresholdFlag,
+	DasSimClientsFlag,
+	DasSimMaxSamplesFlag,
+	DasSimSeedFlag,
+	EncodeParallelismFlag,
+}
+
+var BenchFlags = []cli.Flag{
+	BenchBlobCountsFlag,
+	BenchParallelismFlag,
+	BenchIterationsFlag,
+	BenchJSONFlag,
 }
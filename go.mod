@@ -5,8 +5,10 @@ go 1.23.6
 require (
 	github.com/DillLabs/dill-das v0.0.11
 	github.com/DillLabs/dill-execution v1.23.0
+	github.com/andybalholm/brotli v1.1.1
 	github.com/crate-crypto/go-ipa v0.0.0-20231025140028-3c0104f4b233
 	github.com/holiman/uint256 v1.2.4
+	github.com/klauspost/compress v1.17.6
 	github.com/stretchr/testify v1.9.0
 	github.com/urfave/cli v1.22.10
 )
@@ -51,7 +53,6 @@ require (
 	github.com/ipfs/go-log/v2 v2.5.1 // indirect
 	github.com/ipld/go-ipld-prime v0.20.0 // indirect
 	github.com/jbenet/goprocess v0.1.4 // indirect
-	github.com/klauspost/compress v1.17.6 // indirect
 	github.com/klauspost/cpuid/v2 v2.2.7 // indirect
 	github.com/libp2p/go-buffer-pool v0.1.0 // indirect
 	github.com/libp2p/go-cidranger v1.1.0 // indirect
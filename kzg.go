@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	das "github.com/DillLabs/dill-das"
+	"github.com/DillLabs/dill-execution/common"
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+)
+
+// kzgStageTimes accumulates the time spent in each stage of computeBlobBundle
+// over all workers.
+type kzgStageTimes struct {
+	commitment   atomic.Int64
+	blobProof    atomic.Int64
+	segmentProof atomic.Int64
+}
+
+func (t *kzgStageTimes) add(stage *atomic.Int64, start time.Time) {
+	stage.Add(int64(time.Since(start)))
+}
+
+// computeBlobBundle computes the commitment and versioned hash of every blob,
+// plus the blob proofs and DAS segment proofs selected by opts, spread over
+// opts.Parallelism workers. The results are in the same order as blobs
+// regardless of the number of workers.
+func computeBlobBundle(blobs []kzg4844.Blob, opts EncodeOptions) (*BlobBundle, error) {
+	var (
+		commits         = make([]kzg4844.Commitment, len(blobs))
+		proofs          []kzg4844.Proof
+		segmentProofs   [][]kzg4844.Proof
+		versionedHashes = make([]common.Hash, len(blobs))
+		errs            = make([]error, len(blobs))
+
+		times       kzgStageTimes
+		wg          sync.WaitGroup
+		jobs        = make(chan int)
+		start       = time.Now()
+		parallelism = opts.Parallelism
+	)
+	if !opts.SkipBlobProofs {
+		proofs = make([]kzg4844.Proof, len(blobs))
+	}
+	if opts.SegmentProofs {
+		segmentProofs = make([][]kzg4844.Proof, len(blobs))
+	}
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	if parallelism > len(blobs) {
+		parallelism = len(blobs)
+	}
+	for w := 0; w < parallelism; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				commits[i], errs[i] = computeBlobKZG(&blobs[i], i, proofs, segmentProofs, &times)
+				versionedHashes[i] = kZGToVersionedHash(commits[i])
+			}
+		}()
+	}
+	for i := range blobs {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for i := range blobs {
+		if errs[i] != nil {
+			return nil, fmt.Errorf("blob %d: %w", i, errs[i])
+		}
+	}
+	if opts.LogTimings {
+		log.Printf("kzg: %d blobs with %d workers in %v (commitments %v, blob proofs %v, segment proofs %v)",
+			len(blobs), parallelism, time.Since(start),
+			time.Duration(times.commitment.Load()), time.Duration(times.blobProof.Load()), time.Duration(times.segmentProof.Load()))
+	}
+	return &BlobBundle{
+		Blobs:           blobs,
+		Commitments:     commits,
+		Proofs:          proofs,
+		segmentProofs:   segmentProofs,
+		versionedHashes: versionedHashes,
+	}, nil
+}
+
+// computeBlobKZG returns the commitment of blob i and stores its blob proof
+// and segment proofs in proofs and segmentProofs, unless they are nil.
+func computeBlobKZG(blob *kzg4844.Blob, i int, proofs []kzg4844.Proof, segmentProofs [][]kzg4844.Proof, times *kzgStageTimes) (kzg4844.Commitment, error) {
+	start := time.Now()
+	commit, err := kzg4844.BlobToCommitment(*blob)
+	if err != nil {
+		return kzg4844.Commitment{}, err
+	}
+	times.add(&times.commitment, start)
+
+	if proofs != nil {
+		start = time.Now()
+		if proofs[i], err = kzg4844.ComputeBlobProof(*blob, commit); err != nil {
+			return kzg4844.Commitment{}, err
+		}
+		times.add(&times.blobProof, start)
+	}
+	if segmentProofs != nil {
+		start = time.Now()
+		if segmentProofs[i], err = computeSegmentProofs(blob); err != nil {
+			return kzg4844.Commitment{}, err
+		}
+		times.add(&times.segmentProof, start)
+	}
+	return commit, nil
+}
+
+// computeSegmentProofs returns the marshaled dill-das segment proofs of blob.
+func computeSegmentProofs(blob *kzg4844.Blob) ([]kzg4844.Proof, error) {
+	ep, err := das.BlobToSegmentsProofOnly(blob[:])
+	if err != nil {
+		return nil, err
+	}
+	segmentProofs := make([]kzg4844.Proof, 0, len(ep))
+	for _, p := range ep {
+		segmentProofs = append(segmentProofs, kzg4844.Proof(das.MarshalProof(&p)))
+	}
+	return segmentProofs, nil
+}
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeBlobBundleParallelism(t *testing.T) {
+	blobs, err := canonicalBlobs(RandomFrData(5*blobSize), false)
+	require.NoError(t, err)
+
+	want, err := computeBlobBundle(blobs, EncodeOptions{Parallelism: 1, SegmentProofs: true})
+	require.NoError(t, err)
+	for _, parallelism := range []int{2, 3, 8} {
+		got, err := computeBlobBundle(append([]kzg4844.Blob(nil), blobs...), EncodeOptions{Parallelism: parallelism, SegmentProofs: true})
+		require.NoError(t, err)
+		require.Equal(t, want, got, "parallelism %d", parallelism)
+	}
+}
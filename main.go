@@ -18,6 +18,12 @@ func main() {
 			Action: BlobTxApp,
 			Flags:  TxFlags,
 		},
+		{
+			Name:   "send-raw",
+			Usage:  "send signed raw transactions in nonce order",
+			Action: SendRawApp,
+			Flags:  SendRawFlags,
+		},
 		{
 			Name:   "stress_blob",
 			Usage:  "loop sending blob txs transactions",
@@ -48,6 +54,51 @@ func main() {
 			Action: ProofApp,
 			Flags:  ProofFlags,
 		},
+		{
+			Name:   "verify-point",
+			Usage:  "verify point evaluation proofs locally and with the point evaluation precompile",
+			Action: VerifyPointApp,
+			Flags:  VerifyPointFlags,
+		},
+		{
+			Name:   "encode",
+			Usage:  "encode a file into blob files without sending a transaction",
+			Action: EncodeApp,
+			Flags:  EncodeFlags,
+		},
+		{
+			Name:      "decode",
+			Usage:     "decode blob files back into the original data",
+			ArgsUsage: "<blob-file>...",
+			Action:    DecodeApp,
+			Flags:     DecodeFlags,
+		},
+		{
+			Name:      "segments",
+			Usage:     "write the dill-das segment indices and proofs of sidecar JSON or blob files, without the segment data, which the dill-das proof call used here does not return",
+			ArgsUsage: "<sidecar.json|blob-file>...",
+			Action:    SegmentsApp,
+			Flags:     SegmentsFlags,
+		},
+		{
+			Name:      "verify",
+			Usage:     "verify blob commitments, proofs and versioned hashes of sidecar JSON or blob files",
+			ArgsUsage: "<sidecar.json|blob-file>...",
+			Action:    VerifyApp,
+			Flags:     VerifyFlags,
+		},
+		{
+			Name:   "das-sim",
+			Usage:  "simulate data availability sampling over the dill-das segment proofs of a file, partial: served proofs are compared with the computed ones instead of verified and reconstruction is only a segment count threshold, as segment verification and reconstruction are not available yet",
+			Action: DasSimApp,
+			Flags:  DasSimFlags,
+		},
+		{
+			Name:   "bench",
+			Usage:  "benchmark blob encoding, KZG and dill-das proof throughput",
+			Action: BenchApp,
+			Flags:  BenchFlags,
+		},
 	}
 	das.InitKZGContext()
 
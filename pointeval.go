@@ -0,0 +1,177 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/DillLabs/dill-blob-utils/hex"
+	"github.com/DillLabs/dill-execution/common"
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/DillLabs/dill-execution/params"
+)
+
+// pointEvaluation is a KZG proof that the polynomial of a blob evaluates to Y
+// at Z, as checked by the point evaluation precompile.
+type pointEvaluation struct {
+	BlobIndex     uint64
+	VersionedHash common.Hash
+	Z             kzg4844.Point
+	Y             kzg4844.Claim
+	Commitment    kzg4844.Commitment
+	Proof         kzg4844.Proof
+}
+
+type pointEvaluationJSON struct {
+	BlobIndex      uint64 `json:"blob_index"`
+	VersionedHash  string `json:"versioned_hash"`
+	Z              string `json:"z"`
+	Y              string `json:"y"`
+	Commitment     string `json:"commitment"`
+	Proof          string `json:"proof"`
+	PointEvalInput string `json:"point_eval_input"`
+}
+
+// evaluateBlob computes the point evaluation of blob at every point.
+func evaluateBlob(index uint64, blob kzg4844.Blob, commitment kzg4844.Commitment, points []kzg4844.Point) ([]pointEvaluation, error) {
+	evals := make([]pointEvaluation, 0, len(points))
+	for _, z := range points {
+		proof, y, err := kzg4844.ComputeProof(blob, z)
+		if err != nil {
+			return nil, err
+		}
+		evals = append(evals, pointEvaluation{
+			BlobIndex:     index,
+			VersionedHash: kZGToVersionedHash(commitment),
+			Z:             z,
+			Y:             y,
+			Commitment:    commitment,
+			Proof:         proof,
+		})
+	}
+	return evals, nil
+}
+
+// input returns the 192 byte input of the point evaluation precompile.
+func (e *pointEvaluation) input() []byte {
+	return bytes.Join([][]byte{e.VersionedHash[:], e.Z[:], e.Y[:], e.Commitment[:], e.Proof[:]}, nil)
+}
+
+func (e *pointEvaluation) toJSON() pointEvaluationJSON {
+	return pointEvaluationJSON{
+		BlobIndex:      e.BlobIndex,
+		VersionedHash:  e.VersionedHash.Hex(),
+		Z:              hex.EncodeToHex(e.Z[:]),
+		Y:              hex.EncodeToHex(e.Y[:]),
+		Commitment:     hex.EncodeToHex(e.Commitment[:]),
+		Proof:          hex.EncodeToHex(e.Proof[:]),
+		PointEvalInput: hex.EncodeToHex(e.input()),
+	}
+}
+
+// randomPoints returns n uniformly random field elements.
+func randomPoints(n int) []kzg4844.Point {
+	data := RandomFrData(n * fieldElementSize)
+	points := make([]kzg4844.Point, n)
+	for i := range points {
+		copy(points[i][:], data[i*fieldElementSize:])
+	}
+	return points
+}
+
+// seededPoints returns n field elements derived from seed, the same for every
+// run. Point i is SHA-256(seed || i) reduced modulo the BLS modulus.
+func seededPoints(seed string, n int) []kzg4844.Point {
+	points := make([]kzg4844.Point, n)
+	for i := range points {
+		var idx [8]byte
+		binary.BigEndian.PutUint64(idx[:], uint64(i))
+		h := sha256.Sum256(append([]byte(seed), idx[:]...))
+		points[i] = reduceToPoint(h[:])
+	}
+	return points
+}
+
+// parseInputPoint parses an input point given as 0x prefixed hex, decimal or
+// hash:<string>, which hashes the string to a field element. Exactly 64 hex
+// digits without prefix, the only form accepted before, are still hex. Other
+// hex without the 0x prefix is rejected rather than guessed, since it may also
+// be decimal. Values not below the BLS modulus are reduced with reduce set and
+// rejected otherwise.
+func parseInputPoint(s string, reduce bool) (kzg4844.Point, error) {
+	var v *big.Int
+	switch {
+	case strings.HasPrefix(s, "hash:"):
+		return hashToPoint([]byte(strings.TrimPrefix(s, "hash:"))), nil
+	case strings.HasPrefix(s, "0x"), len(s) == 2*len(kzg4844.Point{}) && isHexString(s):
+		b, err := hex.DecodeHex(s)
+		if err != nil {
+			return kzg4844.Point{}, fmt.Errorf("invalid hex input point %q: %v", s, err)
+		}
+		v = new(big.Int).SetBytes(b)
+	default:
+		var ok bool
+		if v, ok = new(big.Int).SetString(s, 10); !ok || v.Sign() < 0 {
+			if _, ok := new(big.Int).SetString(s, 16); ok {
+				return kzg4844.Point{}, fmt.Errorf("invalid input point %q, hex input points need the 0x prefix", s)
+			}
+			return kzg4844.Point{}, fmt.Errorf("invalid input point %q, expected 0x prefixed hex, decimal or hash:<string>", s)
+		}
+	}
+	if v.Cmp(new(big.Int).SetBytes(blsModulus)) >= 0 {
+		if !reduce {
+			return kzg4844.Point{}, fmt.Errorf("input point %s is not below the BLS modulus, set reduce-point to reduce it", s)
+		}
+		return reduceToPoint(v.Bytes()), nil
+	}
+	var p kzg4844.Point
+	v.FillBytes(p[:])
+	return p, nil
+}
+
+// isHexString reports whether s consists of hex digits only.
+func isHexString(s string) bool {
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+// hashToPoint hashes data to a field element. The 64 bytes
+// SHA-256(0x00 || data) || SHA-256(0x01 || data) are reduced modulo the BLS
+// modulus, which keeps the bias negligible.
+func hashToPoint(data []byte) kzg4844.Point {
+	h0 := sha256.Sum256(append([]byte{0x00}, data...))
+	h1 := sha256.Sum256(append([]byte{0x01}, data...))
+	return reduceToPoint(append(h0[:], h1[:]...))
+}
+
+// blobChallenge returns the Fiat-Shamir challenge at which ComputeBlobProof
+// evaluates blob, so a proof at this point equals the blob proof:
+// SHA-256("FSBLOBVERIFY_V1_" || degree as 16 bytes || blob || commitment)
+// reduced modulo the BLS modulus.
+func blobChallenge(blob kzg4844.Blob, commitment kzg4844.Commitment) kzg4844.Point {
+	var degree [16]byte
+	binary.BigEndian.PutUint64(degree[8:], params.BlobTxFieldElementsPerBlob)
+	h := sha256.New()
+	h.Write([]byte("FSBLOBVERIFY_V1_"))
+	h.Write(degree[:])
+	h.Write(blob[:])
+	h.Write(commitment[:])
+	return reduceToPoint(h.Sum(nil))
+}
+
+// reduceToPoint interprets b as a big endian integer and reduces it modulo
+// the BLS modulus.
+func reduceToPoint(b []byte) kzg4844.Point {
+	var p kzg4844.Point
+	v := new(big.Int).SetBytes(b)
+	v.Mod(v, new(big.Int).SetBytes(blsModulus))
+	v.FillBytes(p[:])
+	return p
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
-	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/DillLabs/dill-blob-utils/hex"
 	gethkzg4844 "github.com/DillLabs/dill-execution/crypto/kzg4844"
@@ -13,46 +17,223 @@ import (
 
 func ProofApp(cliCtx *cli.Context) error {
 	file := cliCtx.String(ProofBlobFileFlag.Name)
-	blobIndex := cliCtx.Uint64(ProofBlobIndexFlag.Name)
-	inputPoint := cliCtx.String(ProofInputPointFlag.Name)
+	inputFormat := cliCtx.String(ProofInputFormatFlag.Name)
+	inputPoints := cliCtx.StringSlice(ProofInputPointFlag.Name)
+	reducePoints := cliCtx.Bool(ProofReducePointFlag.Name)
+	challengePoint := cliCtx.Bool(ProofChallengePointFlag.Name)
+	randomCnt := cliCtx.Int(ProofRandomPointsFlag.Name)
+	pointSeed := cliCtx.String(ProofPointSeedFlag.Name)
+	output := cliCtx.String(ProofOutputFlag.Name)
+	fixturesDir := cliCtx.String(ProofFixturesDirFlag.Name)
+	parallelism := cliCtx.Int(EncodeParallelismFlag.Name)
+	compression, err := ParseCompression(cliCtx.String(EncodeCompressionFlag.Name))
+	if err != nil {
+		return err
+	}
+	format, err := ParseBlobFormat(cliCtx.String(EncodePackingFlag.Name))
+	if err != nil {
+		return err
+	}
+	cache, err := bundleCacheFromFlags(cliCtx)
+	if err != nil {
+		return err
+	}
+	blobIndices, err := proofBlobIndices(cliCtx)
+	if err != nil {
+		return err
+	}
 
-	data, err := os.ReadFile(file)
+	var points []gethkzg4844.Point
+	for _, inputPoint := range inputPoints {
+		x, err := parseInputPoint(inputPoint, reducePoints)
+		if err != nil {
+			return err
+		}
+		points = append(points, x)
+	}
+	if pointSeed != "" {
+		points = append(points, seededPoints(pointSeed, randomCnt)...)
+	} else {
+		points = append(points, randomPoints(randomCnt)...)
+	}
+	if len(points) == 0 && !challengePoint {
+		return errors.New("no input points, set input-point, random-points or challenge-point")
+	}
+
+	opts := EncodeOptions{Parallelism: parallelism, Compression: compression, Format: format, SkipBlobProofs: true, Cache: cache}
+	var (
+		evals    []pointEvaluationJSON
+		fixtures []pointEvaluation
+	)
+	blobs, err := readProofBlobs(file, blobIndices, inputFormat, opts)
 	if err != nil {
-		return fmt.Errorf("error reading blob file: %v", err)
+		return err
 	}
-	blobs, commitments, _, _, versionedHashes, err := EncodeBlobs(data)
+	// the commitments of all selected blobs are computed together, spread
+	// over the workers
+	bundle, err := bundleFor(blobs, opts)
 	if err != nil {
 		log.Fatalf("failed to compute commitments: %v", err)
 	}
+	for i, blobIndex := range blobIndices {
+		blob := blobs[i]
+		blobPoints := points
+		if challengePoint {
+			blobPoints = append(blobPoints[:len(blobPoints):len(blobPoints)], blobChallenge(blob, bundle.Commitments[i]))
+		}
+		e, err := evaluateBlob(blobIndex, blob, bundle.Commitments[i], blobPoints)
+		if err != nil {
+			log.Fatalf("failed to compute proofs: %v", err)
+		}
+		for j := range e {
+			evals = append(evals, e[j].toJSON())
+		}
+		fixtures = append(fixtures, e...)
+	}
+
+	if fixturesDir != "" {
+		if err := writePointEvaluationFixtures(fixturesDir, fixtures); err != nil {
+			return fmt.Errorf("error writing fixtures: %v", err)
+		}
+		log.Printf("wrote fixtures of %d point evaluations to %s", len(fixtures), fixturesDir)
+	}
 
-	if blobIndex >= uint64(len(blobs)) {
-		return fmt.Errorf("error reading %d blob", blobIndex)
+	b, err := json.MarshalIndent(evals, "", "\t")
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		_, err = fmt.Println(string(b))
+		return err
+	}
+	if err := os.WriteFile(output, b, 0644); err != nil {
+		return fmt.Errorf("error writing output file: %v", err)
+	}
+	log.Printf("wrote %d point evaluations of %d blobs to %s", len(evals), len(blobIndices), output)
+	return nil
+}
+
+// proofBlobIndices returns the blob indices selected by the blob-index flag,
+// blob 0 if it is not set.
+func proofBlobIndices(cliCtx *cli.Context) ([]uint64, error) {
+	flags := cliCtx.Int64Slice(ProofBlobIndexFlag.Name)
+	if len(flags) == 0 {
+		return []uint64{0}, nil
+	}
+	indices := make([]uint64, len(flags))
+	for i, idx := range flags {
+		if idx < 0 {
+			return nil, fmt.Errorf("invalid blob index %d", idx)
+		}
+		indices[i] = uint64(idx)
 	}
+	return indices, nil
+}
 
-	if len(inputPoint) != 64 {
-		return fmt.Errorf("wrong input point, len is %d", len(inputPoint))
+// readProofBlobs reads file once as inputFormat and returns the blobs at
+// indices, in the same order: data is encoded with opts, blob and hex hold raw
+// canonical blobs and sidecar is a sidecar JSON whose entries with those
+// indices are used.
+func readProofBlobs(file string, indices []uint64, inputFormat string, opts EncodeOptions) ([]gethkzg4844.Blob, error) {
+	switch inputFormat {
+	case "", "data":
+		return readEncodedBlobs(file, indices, opts)
+	case "blob":
+		opts.Canonical = true
+		return readEncodedBlobs(file, indices, opts)
+	case "hex":
+		r, err := openInput(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading blob file: %v", err)
+		}
+		defer r.Close()
+		text, err := io.ReadAll(r)
+		if err != nil {
+			return nil, fmt.Errorf("error reading blob file: %v", err)
+		}
+		data, err := hex.DecodeHex(strings.TrimSpace(string(text)))
+		if err != nil {
+			return nil, fmt.Errorf("invalid blob hex: %v", err)
+		}
+		blobs, err := canonicalBlobs(data, false)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]gethkzg4844.Blob, len(indices))
+		for i, index := range indices {
+			if index >= uint64(len(blobs)) {
+				return nil, fmt.Errorf("error reading %d blob, file holds %d blobs", index, len(blobs))
+			}
+			out[i] = blobs[index]
+		}
+		return out, nil
+	case "sidecar":
+		sc, err := readSidecarJSON(file)
+		if err != nil {
+			return nil, err
+		}
+		entries := make(map[uint64]*sidecarBlobJSON, len(sc.Blobs))
+		for i := range sc.Blobs {
+			entries[uint64(sc.Blobs[i].Index)] = &sc.Blobs[i]
+		}
+		out := make([]gethkzg4844.Blob, len(indices))
+		for i, index := range indices {
+			e, ok := entries[index]
+			if !ok {
+				return nil, fmt.Errorf("sidecar has no blob with index %d", index)
+			}
+			if out[i], err = e.loadBlob(filepath.Dir(file)); err != nil {
+				return nil, err
+			}
+			if err := checkCanonical([]gethkzg4844.Blob{out[i]}, int(index)); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unknown input format %q, expected data, blob, hex or sidecar", inputFormat)
 	}
+}
 
-	var x gethkzg4844.Point
-	ip, _ := hex.DecodeString(inputPoint)
-	copy(x[:], ip)
-	proof, claimedValue, err := gethkzg4844.ComputeProof(gethkzg4844.Blob(blobs[blobIndex]), x)
+// readEncodedBlobs encodes file with opts, or splits it into raw blobs with
+// opts.Canonical, and returns the blobs at indices. The input is read once, up
+// to the largest index, and no KZG data is computed.
+func readEncodedBlobs(file string, indices []uint64, opts EncodeOptions) ([]gethkzg4844.Blob, error) {
+	r, err := openInput(file)
 	if err != nil {
-		log.Fatalf("failed to compute proofs: %v", err)
+		return nil, fmt.Errorf("error reading blob file: %v", err)
 	}
+	defer r.Close()
 
-	pointEvalInput := bytes.Join(
-		[][]byte{
-			versionedHashes[blobIndex][:],
-			x[:],
-			claimedValue[:],
-			commitments[blobIndex][:],
-			proof[:],
-		},
-		[]byte{},
+	stream, err := NewBlobStream(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close()
+	var (
+		last   uint64
+		wanted = make(map[uint64]bool, len(indices))
+		found  = make(map[uint64]gethkzg4844.Blob, len(indices))
 	)
-	log.Printf(
-		"\nversionedHash %x \n"+"x %x \n"+"y %x \n"+"commitment %x \n"+"proof %x \n"+"pointEvalInput %x",
-		versionedHashes[blobIndex][:], x[:], claimedValue[:], commitments[blobIndex][:], proof[:], pointEvalInput[:])
-	return nil
+	for _, index := range indices {
+		last = max(last, index)
+		wanted[index] = true
+	}
+	for i := uint64(0); i <= last; i++ {
+		blobs, err := stream.NextBlobs(1)
+		if err == io.EOF {
+			return nil, fmt.Errorf("error reading %d blob, input holds %d blobs", last, i)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if wanted[i] {
+			found[i] = blobs[0]
+		}
+	}
+	out := make([]gethkzg4844.Blob, len(indices))
+	for i, index := range indices {
+		out[i] = found[index]
+	}
+	return out, nil
 }
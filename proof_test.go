@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DillLabs/dill-blob-utils/hex"
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadProofBlob(t *testing.T) {
+	dir := t.TempDir()
+	data := makeBlob(blobPayloadSize + 10)
+	blobs := encodeBlobs(data, BlobFormatPacked31, CompressionNone)
+
+	dataFile := filepath.Join(dir, "data")
+	require.NoError(t, os.WriteFile(dataFile, data, 0644))
+	rawFile := filepath.Join(dir, "blobs.bin")
+	require.NoError(t, os.WriteFile(rawFile, append(blobs[0][:], blobs[1][:]...), 0644))
+	hexFile := filepath.Join(dir, "blobs.hex")
+	require.NoError(t, os.WriteFile(hexFile, []byte(hex.EncodeToHex(append(blobs[0][:], blobs[1][:]...))+"\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "blob-0001.bin"), blobs[1][:], 0644))
+	sidecarFile := filepath.Join(dir, "sidecar.json")
+	require.NoError(t, writeSidecarJSON(sidecarFile, &sidecarJSON{Blobs: []sidecarBlobJSON{{Index: 1, BlobFile: "blob-0001.bin"}}}))
+	beaconFile := filepath.Join(dir, "blob_sidecars.json")
+	beacon, err := json.Marshal(map[string]any{"data": []map[string]any{
+		{"index": "0", "blob": hex.EncodeToHex(blobs[0][:]), "kzg_commitment": "0xc0", "kzg_proof": "0xc0", "kzg_commitment_inclusion_proof": []string{}},
+		{"index": "1", "blob": hex.EncodeToHex(blobs[1][:]), "kzg_commitment": "0xc0", "kzg_proof": "0xc0", "signed_block_header": map[string]any{}},
+	}})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(beaconFile, beacon, 0644))
+
+	for _, tc := range []struct {
+		format, file string
+	}{
+		{"data", dataFile},
+		{"blob", rawFile},
+		{"hex", hexFile},
+		{"sidecar", sidecarFile},
+		{"sidecar", beaconFile},
+	} {
+		got, err := readProofBlobs(tc.file, []uint64{1}, tc.format, EncodeOptions{})
+		require.NoError(t, err, tc.format)
+		require.Equal(t, blobs[1:2], got, tc.format)
+
+		_, err = readProofBlobs(tc.file, []uint64{1, 2}, tc.format, EncodeOptions{})
+		require.Error(t, err, tc.format)
+	}
+	// several indices are read in one pass, in the order given
+	for _, format := range []string{"data", "blob", "hex"} {
+		file := map[string]string{"data": dataFile, "blob": rawFile, "hex": hexFile}[format]
+		got, err := readProofBlobs(file, []uint64{1, 0, 1}, format, EncodeOptions{})
+		require.NoError(t, err, format)
+		require.Equal(t, []kzg4844.Blob{blobs[1], blobs[0], blobs[1]}, got, format)
+	}
+	_, err = readProofBlobs(dataFile, []uint64{0}, "rlp", EncodeOptions{})
+	require.Error(t, err)
+
+	// sidecars are read from stdin like the other formats
+	f, err := os.Open(beaconFile)
+	require.NoError(t, err)
+	defer f.Close()
+	stdin := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = stdin }()
+	got, err := readProofBlobs("-", []uint64{1}, "sidecar", EncodeOptions{})
+	require.NoError(t, err)
+	require.Equal(t, blobs[1:2], got)
+}
+
+func TestPointEvaluation(t *testing.T) {
+	points := seededPoints("vectors", 20)
+	require.Equal(t, points, seededPoints("vectors", 20))
+	require.NotEqual(t, points[0], points[1])
+	for _, p := range points {
+		require.Negative(t, bytes.Compare(p[:], blsModulus))
+	}
+	over := append([]byte{0}, blsModulus...)
+	over[len(over)-1]++
+	require.Equal(t, kzg4844.Point{31: 1}, reduceToPoint(over))
+
+	blob := encodeBlob([]byte("point evaluation"), BlobFormatPacked31, CompressionNone)
+	evals, err := evaluateBlob(3, blob, kzg4844.Commitment{1}, points[:2])
+	require.NoError(t, err)
+	require.Len(t, evals, 2)
+	input := evals[1].input()
+	require.Len(t, input, 192)
+	require.Equal(t, points[1][:], input[32:64])
+	require.Equal(t, uint64(3), evals[1].toJSON().BlobIndex)
+}
+
+func TestVerifyPointEvaluation(t *testing.T) {
+	blob := encodeBlob([]byte("point evaluation"), BlobFormatPacked31, CompressionNone)
+	commit, err := kzg4844.BlobToCommitment(blob)
+	require.NoError(t, err)
+	evals, err := evaluateBlob(0, blob, commit, seededPoints("verify", 1))
+	require.NoError(t, err)
+
+	in := evals[0].input()
+	e, err := parsePointEvalInput(in)
+	require.NoError(t, err)
+	require.Equal(t, evals[0], *e)
+	require.NoError(t, verifyPointEvaluationLocal(in))
+
+	in[0] ^= 0xff
+	require.ErrorContains(t, verifyPointEvaluationLocal(in), "versioned hash")
+	_, err = parsePointEvalInput(in[:191])
+	require.Error(t, err)
+
+	ret := pointEvaluationReturn()
+	require.Equal(t, uint64(4096), new(big.Int).SetBytes(ret[:32]).Uint64())
+	require.Equal(t, blsModulus, ret[32:])
+}
+
+func TestParseInputPoint(t *testing.T) {
+	five := kzg4844.Point{31: 5}
+	for _, s := range []string{"5", "0x05", "0x5", "0000000000000000000000000000000000000000000000000000000000000005"} {
+		p, err := parseInputPoint(s, false)
+		require.NoError(t, err, s)
+		require.Equal(t, five, p, s)
+	}
+
+	modulusPlus5 := new(big.Int).Add(new(big.Int).SetBytes(blsModulus), big.NewInt(5))
+	_, err := parseInputPoint(modulusPlus5.String(), false)
+	require.ErrorContains(t, err, "not below the BLS modulus")
+	p, err := parseInputPoint(modulusPlus5.String(), true)
+	require.NoError(t, err)
+	require.Equal(t, five, p)
+	_, err = parseInputPoint(hex.EncodeToHex(blsModulus), false)
+	require.Error(t, err)
+
+	p, err = parseInputPoint("hash:dill", false)
+	require.NoError(t, err)
+	require.Equal(t, hashToPoint([]byte("dill")), p)
+	require.Negative(t, bytes.Compare(p[:], blsModulus))
+
+	for _, s := range []string{"", "-1", "0xzz", "five"} {
+		_, err := parseInputPoint(s, true)
+		require.Error(t, err, s)
+	}
+
+	// 64 digits stay hex as before, other unprefixed hex is rejected rather
+	// than guessed
+	p, err = parseInputPoint("0000000000000000000000000000000000000000000000000000000000000010", false)
+	require.NoError(t, err)
+	require.Equal(t, kzg4844.Point{31: 0x10}, p)
+	p, err = parseInputPoint("000000000000000000000000000000000000000000000000000000000000000a", false)
+	require.NoError(t, err)
+	require.Equal(t, kzg4844.Point{31: 0x0a}, p)
+	p, err = parseInputPoint("10", false)
+	require.NoError(t, err)
+	require.Equal(t, kzg4844.Point{31: 10}, p)
+	_, err = parseInputPoint("1a", false)
+	require.ErrorContains(t, err, "0x prefix")
+}
+
+func TestWritePointEvaluationFixtures(t *testing.T) {
+	dir := t.TempDir()
+	blob := encodeBlob([]byte("fixtures"), BlobFormatPacked31, CompressionNone)
+	commitment := kzg4844.Commitment{0xc0}
+	evals, err := evaluateBlob(0, blob, commitment, seededPoints("fixtures", 2))
+	require.NoError(t, err)
+	require.NoError(t, writePointEvaluationFixtures(dir, evals))
+
+	b, err := os.ReadFile(filepath.Join(dir, "point_evaluations.json"))
+	require.NoError(t, err)
+	var fixtures pointEvaluationFixturesJSON
+	require.NoError(t, json.Unmarshal(b, &fixtures))
+	require.Len(t, fixtures.Valid, 2)
+	require.Len(t, fixtures.Invalid, 2*len(pointEvaluationCorruptions))
+	require.Equal(t, evals[0].toJSON().PointEvalInput, fixtures.Valid[0].Input)
+	for _, f := range fixtures.Invalid {
+		require.NotContains(t, []string{fixtures.Valid[0].Input, fixtures.Valid[1].Input}, f.Input, f.Name)
+	}
+	require.Equal(t, hex.EncodeToHex(blsModulus), fixtures.Invalid[len(pointEvaluationCorruptions)-1].Z)
+
+	sol, err := os.ReadFile(filepath.Join(dir, "PointEvaluationFixtures.sol"))
+	require.NoError(t, err)
+	require.Contains(t, string(sol), "bytes32 internal constant VALID_1_Z = "+fixtures.Valid[1].Z+";")
+	require.Contains(t, string(sol), "WRONG_PROOF_0_INPUT = hex\""+fixtures.Invalid[2].Input[2:]+"\";")
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/DillLabs/dill-blob-utils/hex"
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/urfave/cli"
+)
+
+// segmentsJSON lists the dill-das segment proofs of one blob. It holds no
+// segment data: das.BlobToSegmentsProofOnly, the only dill-das call used here,
+// returns the proofs alone, and the extended segments cannot be recomputed
+// without the dill-das erasure coding layout.
+type segmentsJSON struct {
+	BlobIndex     int                `json:"blob_index"`
+	KZGCommitment string             `json:"kzg_commitment"`
+	VersionedHash string             `json:"versioned_hash"`
+	Segments      []segmentProofJSON `json:"segments"`
+}
+
+type segmentProofJSON struct {
+	Index int    `json:"index"`
+	Proof string `json:"proof"`
+}
+
+// SegmentsApp writes the segment indices and marshaled segment proofs of
+// every blob of the given sidecar JSON or raw blob files, one file per blob.
+// The binary format holds the proofs back to back, segment i at offset
+// i*48. The segments themselves are not written, see segmentsJSON.
+func SegmentsApp(cliCtx *cli.Context) error {
+	outputDir := cliCtx.String(SegmentsOutputDirFlag.Name)
+	format := cliCtx.String(SegmentsFormatFlag.Name)
+	parallelism := cliCtx.Int(EncodeParallelismFlag.Name)
+	cache, err := bundleCacheFromFlags(cliCtx)
+	if err != nil {
+		return err
+	}
+	if format != "json" && format != "binary" {
+		return fmt.Errorf("unknown segments format %q, expected json or binary", format)
+	}
+	files := cliCtx.Args()
+	if len(files) == 0 {
+		return errors.New("no sidecar or blob files given")
+	}
+
+	claims, err := loadBlobClaims(files)
+	if err != nil {
+		return err
+	}
+	blobs := make([]kzg4844.Blob, len(claims))
+	for i := range claims {
+		blobs[i] = claims[i].blob
+	}
+	if err := checkCanonical(blobs, 0); err != nil {
+		return err
+	}
+	bundle, err := bundleFor(blobs, EncodeOptions{Parallelism: parallelism, SkipBlobProofs: true, SegmentProofs: true, Cache: cache})
+	if err != nil {
+		return fmt.Errorf("failed to compute segment proofs: %v", err)
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("error creating output dir: %v", err)
+	}
+	for i := 0; i < bundle.Len(); i++ {
+		proofs := bundle.SegmentProofs()[i]
+		var (
+			file string
+			b    []byte
+		)
+		if format == "binary" {
+			file = fmt.Sprintf("segments-%04d.bin", i)
+			var buf bytes.Buffer
+			for _, p := range proofs {
+				buf.Write(p[:])
+			}
+			b = buf.Bytes()
+		} else {
+			file = fmt.Sprintf("segments-%04d.json", i)
+			if b, err = json.MarshalIndent(newSegmentsJSON(i, bundle), "", "\t"); err != nil {
+				return err
+			}
+		}
+		if err := os.WriteFile(filepath.Join(outputDir, file), b, 0644); err != nil {
+			return fmt.Errorf("error writing segments of blob %d: %v", i, err)
+		}
+		log.Printf("%s: %d segment proofs (%s)", claims[i].name, len(proofs), file)
+	}
+	return nil
+}
+
+func newSegmentsJSON(i int, bundle *BlobBundle) *segmentsJSON {
+	s := &segmentsJSON{
+		BlobIndex:     i,
+		KZGCommitment: hex.EncodeToHex(bundle.Commitments[i][:]),
+		VersionedHash: bundle.VersionedHashes()[i].Hex(),
+	}
+	for j, p := range bundle.SegmentProofs()[i] {
+		s.Segments = append(s.Segments, segmentProofJSON{Index: j, Proof: hex.EncodeToHex(p[:])})
+	}
+	return s
+}
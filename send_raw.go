@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DillLabs/dill-blob-utils/hex"
+	"github.com/DillLabs/dill-execution/common"
+	"github.com/DillLabs/dill-execution/core/types"
+	"github.com/DillLabs/dill-execution/ethclient"
+	"github.com/urfave/cli"
+)
+
+// SendRawApp broadcasts signed raw txs, such as those written by tx --offline,
+// in nonce order and waits for their receipts.
+func SendRawApp(cliCtx *cli.Context) error {
+	addr := cliCtx.String(TxRPCURLFlag.Name)
+	files := cliCtx.StringSlice(SendRawTxFileFlag.Name)
+	wait := cliCtx.BoolT(TxBlobWaitInclusionFlag.Name)
+	if len(files) == 0 {
+		return errors.New("no raw tx files given")
+	}
+
+	txs, err := readRawTxs(files)
+	if err != nil {
+		return err
+	}
+	if len(txs) == 0 {
+		return errors.New("no raw txs found")
+	}
+	sortByNonce(txs)
+
+	ctx := context.Background()
+	client, err := ethclient.DialContext(ctx, addr)
+	if err != nil {
+		log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+	}
+
+	start := time.Now()
+	for _, tx := range txs {
+		raw, err := tx.MarshalBinary()
+		if err != nil {
+			return fmt.Errorf("failed to encode transaction %v: %v", tx.Hash(), err)
+		}
+		var hash common.Hash
+		if err := client.Client().CallContext(ctx, &hash, "eth_sendRawTransaction", hex.EncodeToHex(raw)); err != nil {
+			return fmt.Errorf("failed to send transaction nonce=%d: %v", tx.Nonce(), err)
+		}
+		log.Printf("successfully sent transaction. nonce=%d txhash=%v", tx.Nonce(), hash)
+	}
+	if !wait {
+		return nil
+	}
+	for _, tx := range txs {
+		if err := waitForReceipt(client, tx.Hash(), start); err != nil {
+			return fmt.Errorf("failed to get receipt of %v: %v", tx.Hash(), err)
+		}
+	}
+	return nil
+}
+
+// rawTxExts are the extensions of the files read from a raw tx directory.
+var rawTxExts = map[string]bool{".hex": true, ".txt": true, ".raw": true, ".rlp": true, ".bin": true}
+
+// writeRawTx writes tx as a line of hex in the format read by readRawTxs. The
+// sidecar of a blob tx is kept, so this is the network encoding accepted by
+// eth_sendRawTransaction.
+func writeRawTx(w io.Writer, tx *types.Transaction) error {
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode transaction: %v", err)
+	}
+	if _, err := fmt.Fprintln(w, hex.EncodeToHex(raw)); err != nil {
+		return fmt.Errorf("error writing raw transaction: %v", err)
+	}
+	return nil
+}
+
+// sortByNonce orders txs by nonce, keeping the file order of equal nonces.
+func sortByNonce(txs []*types.Transaction) {
+	sort.SliceStable(txs, func(i, j int) bool { return txs[i].Nonce() < txs[j].Nonce() })
+}
+
+// readRawTxs decodes the raw txs of files. A directory adds the regular files
+// in it with one of rawTxExts, skipping hidden files, - reads stdin.
+func readRawTxs(files []string) ([]*types.Transaction, error) {
+	var txs []*types.Transaction
+	for _, file := range files {
+		paths := []string{file}
+		if file != "-" {
+			info, err := os.Stat(file)
+			if err != nil {
+				return nil, fmt.Errorf("error reading raw tx file: %v", err)
+			}
+			if info.IsDir() {
+				entries, err := os.ReadDir(file)
+				if err != nil {
+					return nil, fmt.Errorf("error reading raw tx directory: %v", err)
+				}
+				paths = paths[:0]
+				for _, e := range entries {
+					if !e.Type().IsRegular() {
+						continue
+					}
+					if strings.HasPrefix(e.Name(), ".") || !rawTxExts[strings.ToLower(filepath.Ext(e.Name()))] {
+						log.Printf("skipping %s, not a raw tx file", filepath.Join(file, e.Name()))
+						continue
+					}
+					paths = append(paths, filepath.Join(file, e.Name()))
+				}
+			}
+		}
+		for _, path := range paths {
+			r, err := openInput(path)
+			if err != nil {
+				return nil, fmt.Errorf("error reading raw tx file: %v", err)
+			}
+			data, err := io.ReadAll(r)
+			r.Close()
+			if err != nil {
+				return nil, fmt.Errorf("error reading raw tx file: %v", err)
+			}
+			raws, err := splitRawTxs(data)
+			if err != nil {
+				return nil, fmt.Errorf("invalid raw tx file %s: %v", path, err)
+			}
+			for i, raw := range raws {
+				tx := new(types.Transaction)
+				if err := tx.UnmarshalBinary(raw); err != nil {
+					return nil, fmt.Errorf("invalid raw tx %d in %s: %v", i, path, err)
+				}
+				txs = append(txs, tx)
+			}
+		}
+	}
+	return txs, nil
+}
+
+// splitRawTxs returns the encoded txs of a raw tx file, either hex with one tx
+// per line, with or without 0x prefix, or a single binary tx. Binary txs
+// start with a small type byte or an RLP list header of 0xc0 and above,
+// neither of which is hex text.
+func splitRawTxs(data []byte) ([][]byte, error) {
+	if !isHexText(data) {
+		return [][]byte{data}, nil
+	}
+	var raws [][]byte
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		raw, err := hex.DecodeHex(string(line))
+		if err != nil {
+			return nil, err
+		}
+		raws = append(raws, raw)
+	}
+	return raws, nil
+}
+
+// isHexText reports whether data holds only hex digits, 0x prefixes and
+// whitespace.
+func isHexText(data []byte) bool {
+	text := false
+	for _, c := range data {
+		switch {
+		case '0' <= c && c <= '9', 'a' <= c && c <= 'f', 'A' <= c && c <= 'F':
+			text = true
+		case c == 'x' || c == ' ' || c == '\t' || c == '\r' || c == '\n':
+		default:
+			return false
+		}
+	}
+	return text
+}
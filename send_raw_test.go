@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bytes"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/DillLabs/dill-execution/core/types"
+	"github.com/DillLabs/dill-execution/crypto"
+	"github.com/holiman/uint256"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRawTxsRoundTrip(t *testing.T) {
+	bundle, err := computeBlobBundle(encodeBlobs(makeBlob(blobPayloadSize), BlobFormatPacked31, CompressionNone), EncodeOptions{Parallelism: 2})
+	require.NoError(t, err)
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	chainID := big.NewInt(1)
+
+	// the files of a tx --offline run, out of nonce order, next to stray files
+	dir := t.TempDir()
+	files := map[string][]uint64{"txs-a.hex": {2, 0}, "txs-b.txt": {1}}
+	for name, nonces := range files {
+		var buf bytes.Buffer
+		for _, nonce := range nonces {
+			tx, err := types.SignTx(types.NewTx(&types.BlobTx{
+				ChainID:    uint256.MustFromBig(chainID),
+				Nonce:      nonce,
+				GasTipCap:  uint256.NewInt(1),
+				GasFeeCap:  uint256.NewInt(1),
+				Gas:        21000,
+				Value:      uint256.NewInt(0),
+				BlobFeeCap: uint256.NewInt(1),
+				BlobHashes: bundle.VersionedHashes(),
+				Sidecar:    bundle.Sidecar(),
+			}), types.NewCancunSigner(chainID), key)
+			require.NoError(t, err)
+			require.NoError(t, writeRawTx(&buf, tx))
+		}
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), buf.Bytes(), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README"), []byte("signed txs\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".gitkeep"), nil, 0644))
+
+	txs, err := readRawTxs([]string{dir})
+	require.NoError(t, err)
+	sortByNonce(txs)
+	require.Len(t, txs, 3)
+	for i, tx := range txs {
+		require.Equal(t, uint64(i), tx.Nonce())
+		require.NotNil(t, tx.BlobTxSidecar())
+		require.Equal(t, bundle.Blobs, tx.BlobTxSidecar().Blobs)
+		require.Equal(t, bundle.Commitments, tx.BlobTxSidecar().Commitments)
+		require.Equal(t, bundle.Proofs, tx.BlobTxSidecar().Proofs)
+	}
+}
+
+func TestSplitRawTxs(t *testing.T) {
+	raws, err := splitRawTxs([]byte("0x02aa\n\n0x03bbcc\n"))
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{0x02, 0xaa}, {0x03, 0xbb, 0xcc}}, raws)
+
+	raws, err = splitRawTxs([]byte{0x03, '0', 'x'})
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{0x03, '0', 'x'}}, raws)
+
+	raws, err = splitRawTxs([]byte("02aa\r\n03BBCC"))
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{{0x02, 0xaa}, {0x03, 0xbb, 0xcc}}, raws)
+
+	_, err = splitRawTxs([]byte("0x02aa\n0x0x03"))
+	require.Error(t, err)
+}
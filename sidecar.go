@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/DillLabs/dill-blob-utils/hex"
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+)
+
+// sidecarJSON is the on-disk description of a set of blobs written next to
+// the raw blob files.
+type sidecarJSON struct {
+	Blobs []sidecarBlobJSON `json:"blobs"`
+}
+
+// sidecarBlobJSON describes one blob. The blob itself is either stored in
+// BlobFile, relative to the sidecar, or inline in Blob.
+type sidecarBlobJSON struct {
+	Index         int      `json:"index"`
+	BlobFile      string   `json:"blob_file,omitempty"`
+	Blob          string   `json:"blob,omitempty"`
+	Format        string   `json:"format,omitempty"`
+	KZGCommitment string   `json:"kzg_commitment"`
+	KZGProof      string   `json:"kzg_proof"`
+	VersionedHash string   `json:"versioned_hash"`
+	SegmentProofs []string `json:"segment_proofs,omitempty"`
+}
+
+// newSidecarJSON describes the blobs of bundle stored in blobFiles. The DAS
+// segment proofs are only included with withSegmentProofs set.
+func newSidecarJSON(blobFiles []string, format BlobFormat, bundle *BlobBundle, withSegmentProofs bool) *sidecarJSON {
+	sc := &sidecarJSON{}
+	sc.add(blobFiles, format, bundle, withSegmentProofs)
+	return sc
+}
+
+// add appends the blobs of bundle stored in blobFiles, numbering them after
+// the blobs already in sc. Only the KZG data is kept, not the blobs, so a
+// sidecar can be built a bundle at a time without holding every blob.
+func (sc *sidecarJSON) add(blobFiles []string, format BlobFormat, bundle *BlobBundle, withSegmentProofs bool) {
+	for i := 0; i < bundle.Len(); i++ {
+		entry := sidecarBlobJSON{
+			Index:         len(sc.Blobs),
+			BlobFile:      blobFiles[i],
+			Format:        format.String(),
+			KZGCommitment: hex.EncodeToHex(bundle.Commitments[i][:]),
+			KZGProof:      hex.EncodeToHex(bundle.Proofs[i][:]),
+			VersionedHash: bundle.VersionedHashes()[i].Hex(),
+		}
+		if withSegmentProofs {
+			for _, p := range bundle.SegmentProofs()[i] {
+				entry.SegmentProofs = append(entry.SegmentProofs, hex.EncodeToHex(p[:]))
+			}
+		}
+		sc.Blobs = append(sc.Blobs, entry)
+	}
+}
+
+// beaconBlobSidecarJSON is a blob sidecar as returned in the data list of the
+// beacon API /eth/v1/beacon/blob_sidecars/{block_id}. The block header and
+// inclusion proof are not needed and ignored.
+type beaconBlobSidecarJSON struct {
+	Index         string `json:"index"`
+	Blob          string `json:"blob"`
+	KZGCommitment string `json:"kzg_commitment"`
+	KZGProof      string `json:"kzg_proof"`
+}
+
+// readSidecarJSON reads a sidecar written by this tool or a beacon API
+// blob_sidecars response. Beacon sidecars carry no versioned hash, their
+// entries leave it empty. A file of - reads stdin.
+func readSidecarJSON(file string) (*sidecarJSON, error) {
+	r, err := openInput(file)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var sc struct {
+		sidecarJSON
+		Data []beaconBlobSidecarJSON `json:"data"`
+	}
+	if err := json.Unmarshal(b, &sc); err != nil {
+		return nil, fmt.Errorf("invalid sidecar %s: %v", file, err)
+	}
+	for _, e := range sc.Data {
+		index, err := strconv.Atoi(e.Index)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sidecar %s: invalid index %q", file, e.Index)
+		}
+		sc.Blobs = append(sc.Blobs, sidecarBlobJSON{
+			Index:         index,
+			Blob:          e.Blob,
+			KZGCommitment: e.KZGCommitment,
+			KZGProof:      e.KZGProof,
+		})
+	}
+	return &sc.sidecarJSON, nil
+}
+
+// loadBlob returns the blob described by e, reading BlobFile relative to dir.
+func (e *sidecarBlobJSON) loadBlob(dir string) (kzg4844.Blob, error) {
+	var (
+		b   []byte
+		err error
+	)
+	switch {
+	case e.Blob != "":
+		b, err = hex.DecodeHex(e.Blob)
+	case e.BlobFile != "":
+		file := e.BlobFile
+		if !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		b, err = os.ReadFile(file)
+	default:
+		return kzg4844.Blob{}, fmt.Errorf("blob %d has neither blob nor blob_file", e.Index)
+	}
+	if err != nil {
+		return kzg4844.Blob{}, fmt.Errorf("error reading blob %d: %v", e.Index, err)
+	}
+	if len(b) != blobSize {
+		return kzg4844.Blob{}, fmt.Errorf("blob %d has %d bytes, expected %d", e.Index, len(b), blobSize)
+	}
+	return kzg4844.Blob(b), nil
+}
+
+func writeSidecarJSON(file string, sc *sidecarJSON) error {
+	b, err := json.MarshalIndent(sc, "", "\t")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(file, b, 0644)
+}
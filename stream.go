@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+)
+
+// BlobStream encodes data read from an io.Reader into blobs a few at a time,
+// so inputs of any size can be processed with constant memory. Every blob is
+// encoded independently, decoding them in order with DecodeBlobs yields the
+// original data.
+type BlobStream struct {
+	input *countingReader
+	r     io.ReadCloser
+	opts  EncodeOptions
+	buf   []byte
+
+	blobs       int
+	payloadSize int64
+	done        bool
+	preloaded   []kzg4844.Blob
+}
+
+// NewBlobStream returns a stream reading from r. With opts.Canonical set, r
+// must contain raw blobs which are checked and used as is, otherwise the data is
+// compressed with opts.Compression on the fly and packed with opts.Format.
+func NewBlobStream(r io.Reader, opts EncodeOptions) (*BlobStream, error) {
+	s := &BlobStream{
+		input: &countingReader{r: r},
+		opts:  opts,
+	}
+	if opts.Canonical {
+		s.r = io.NopCloser(s.input)
+		s.buf = make([]byte, blobSize)
+		return s, nil
+	}
+	cr, err := compressReader(s.input, opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	s.r = cr
+	s.buf = make([]byte, opts.Format.payloadSize())
+	return s, nil
+}
+
+// Next encodes up to n blobs and computes their commitments, proofs, DAS
+// segment proofs and versioned hashes, or loads them from opts.Cache. It
+// returns io.EOF once the input is exhausted.
+func (s *BlobStream) Next(n int) (*BlobBundle, error) {
+	blobs, err := s.NextBlobs(n)
+	if err != nil {
+		return nil, err
+	}
+	return bundleFor(blobs, s.opts)
+}
+
+// NextBlobs encodes up to n blobs without computing any KZG data. It returns
+// io.EOF once the input is exhausted.
+func (s *BlobStream) NextBlobs(n int) ([]kzg4844.Blob, error) {
+	var blobs []kzg4844.Blob
+	for len(blobs) < n && len(s.preloaded) > 0 {
+		blobs = append(blobs, s.preloaded[0])
+		s.preloaded = s.preloaded[1:]
+	}
+	for len(blobs) < n && !s.done {
+		blob, ok, err := s.readBlob()
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			break
+		}
+		blobs = append(blobs, blob)
+	}
+	if len(blobs) == 0 {
+		return nil, io.EOF
+	}
+	return blobs, nil
+}
+
+// Preload reads and checks the rest of the input up front, so that invalid
+// canonical input is reported before any blob of it is used. The blobs are
+// then returned by the following calls to Next and NextBlobs.
+func (s *BlobStream) Preload() error {
+	for !s.done {
+		blob, ok, err := s.readBlob()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+		s.preloaded = append(s.preloaded, blob)
+	}
+	return nil
+}
+
+// Close releases the compression of the stream. It does not close the
+// underlying reader.
+func (s *BlobStream) Close() error {
+	return s.r.Close()
+}
+
+// Blobs returns the number of blobs produced so far.
+func (s *BlobStream) Blobs() int {
+	return s.blobs
+}
+
+// Size returns the number of input bytes consumed so far.
+func (s *BlobStream) Size() int64 {
+	return s.input.n
+}
+
+// PayloadSize returns the number of bytes packed into blobs so far, which is
+// less than Size for compressed streams.
+func (s *BlobStream) PayloadSize() int64 {
+	return s.payloadSize
+}
+
+func (s *BlobStream) readBlob() (kzg4844.Blob, bool, error) {
+	n, err := io.ReadFull(s.r, s.buf)
+	s.payloadSize += int64(n)
+	switch err {
+	case nil:
+	case io.EOF:
+		s.done = true
+		if s.blobs != 0 {
+			return kzg4844.Blob{}, false, nil
+		}
+		if s.opts.Canonical {
+			return kzg4844.Blob{}, false, errNoBlobData
+		}
+		// like encodeBlobs, empty input still yields one empty blob
+	case io.ErrUnexpectedEOF:
+		s.done = true
+		if s.opts.Canonical {
+			if !s.opts.Pad {
+				return kzg4844.Blob{}, false, fmt.Errorf("trailing %d bytes do not fill a blob, blob data must be a multiple of %d bytes", n, blobSize)
+			}
+			clear(s.buf[n:])
+		}
+	default:
+		return kzg4844.Blob{}, false, err
+	}
+
+	s.blobs++
+	if s.opts.Canonical {
+		blob := kzg4844.Blob(s.buf)
+		if err := checkCanonical([]kzg4844.Blob{blob}, s.blobs-1); err != nil {
+			return kzg4844.Blob{}, false, err
+		}
+		return blob, true, nil
+	}
+	return encodeBlob(s.buf[:n], s.opts.Format, s.opts.Compression), true, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// openInput opens file for reading, "-" reads from stdin.
+func openInput(file string) (io.ReadCloser, error) {
+	if file == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(file)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlobStream(t *testing.T) {
+	data := makeBlob(3*blobPayloadSize + 7)
+	stream, err := NewBlobStream(bytes.NewReader(data), EncodeOptions{})
+	require.NoError(t, err)
+
+	var blobs [][]byte
+	for {
+		bs, err := stream.NextBlobs(2)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(bs), 2)
+		blobs = append(blobs, blobsToBytes(bs)...)
+	}
+	require.Len(t, blobs, 4)
+	require.Equal(t, 4, stream.Blobs())
+	require.Equal(t, int64(len(data)), stream.Size())
+
+	dec, err := DecodeBlobs(blobs)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(data, dec))
+}
+
+func TestBlobStreamEmpty(t *testing.T) {
+	stream, err := NewBlobStream(bytes.NewReader(nil), EncodeOptions{})
+	require.NoError(t, err)
+	blobs, err := stream.NextBlobs(6)
+	require.NoError(t, err)
+	require.Len(t, blobs, 1)
+
+	stream, err = NewBlobStream(bytes.NewReader(nil), EncodeOptions{Canonical: true})
+	require.NoError(t, err)
+	_, err = stream.NextBlobs(6)
+	require.ErrorIs(t, err, errNoBlobData)
+}
+
+func TestBlobStreamCanonical(t *testing.T) {
+	data := RandomFrData(2 * blobSize)
+	stream, err := NewBlobStream(bytes.NewReader(data), EncodeOptions{Canonical: true})
+	require.NoError(t, err)
+	blobs, err := stream.NextBlobs(6)
+	require.NoError(t, err)
+	require.Len(t, blobs, 2)
+	require.Equal(t, data[blobSize:], blobs[1][:])
+
+	stream, err = NewBlobStream(bytes.NewReader(data[:blobSize+10]), EncodeOptions{Canonical: true})
+	require.NoError(t, err)
+	_, err = stream.NextBlobs(6)
+	require.Error(t, err)
+
+	stream, err = NewBlobStream(bytes.NewReader(data[:blobSize+10]), EncodeOptions{Canonical: true, Pad: true})
+	require.NoError(t, err)
+	blobs, err = stream.NextBlobs(6)
+	require.NoError(t, err)
+	require.Len(t, blobs, 2)
+	require.Equal(t, make([]byte, blobSize-10), blobs[1][10:])
+
+	copy(data[fieldElementSize:], blsModulus)
+	stream, err = NewBlobStream(bytes.NewReader(data), EncodeOptions{Canonical: true})
+	require.NoError(t, err)
+	_, err = stream.NextBlobs(6)
+	require.EqualError(t, err, "1 field elements are not below the BLS modulus: blob 0 element 1")
+
+	// a bad last blob fails the preload before the first blob is returned
+	data = RandomFrData(3 * blobSize)
+	copy(data[2*blobSize:], blsModulus)
+	stream, err = NewBlobStream(bytes.NewReader(data), EncodeOptions{Canonical: true})
+	require.NoError(t, err)
+	require.EqualError(t, stream.Preload(), "1 field elements are not below the BLS modulus: blob 2 element 0")
+
+	stream, err = NewBlobStream(bytes.NewReader(data[:2*blobSize]), EncodeOptions{Canonical: true})
+	require.NoError(t, err)
+	require.NoError(t, stream.Preload())
+	blobs, err = stream.NextBlobs(1)
+	require.NoError(t, err)
+	require.Equal(t, data[:blobSize], blobs[0][:])
+	blobs, err = stream.NextBlobs(6)
+	require.NoError(t, err)
+	require.Len(t, blobs, 1)
+	_, err = stream.NextBlobs(6)
+	require.Equal(t, io.EOF, err)
+}
+
+func TestBlobStreamCompression(t *testing.T) {
+	data := bytes.Repeat([]byte(`{"level":"info","msg":"blob stream test"}`+"\n"), 20000)
+	for _, c := range []Compression{CompressionZstd, CompressionBrotli} {
+		t.Run(c.String(), func(t *testing.T) {
+			stream, err := NewBlobStream(bytes.NewReader(data), EncodeOptions{Compression: c})
+			require.NoError(t, err)
+			var blobs [][]byte
+			for {
+				bs, err := stream.NextBlobs(6)
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				blobs = append(blobs, blobsToBytes(bs)...)
+			}
+			require.Equal(t, int64(len(data)), stream.Size())
+			require.Less(t, stream.PayloadSize(), stream.Size())
+			require.Less(t, len(blobs), BlobFormatPacked31.blobsNeeded(stream.Size()))
+
+			dec, err := DecodeBlobs(blobs)
+			require.NoError(t, err)
+			require.True(t, bytes.Equal(data, dec))
+		})
+	}
+}
@@ -7,11 +7,11 @@ import (
 	"fmt"
 	"log"
 	"math/big"
+	"runtime"
 	"strings"
 	"time"
 
 	"github.com/DillLabs/dill-blob-utils/hex"
-	das "github.com/DillLabs/dill-das"
 	ethereum "github.com/DillLabs/dill-execution"
 	"github.com/DillLabs/dill-execution/accounts/abi/bind"
 	"github.com/DillLabs/dill-execution/common"
@@ -24,65 +24,60 @@ import (
 	"github.com/holiman/uint256"
 )
 
-func encodeBlobs(data []byte) []kzg4844.Blob {
-	blobs := []kzg4844.Blob{{}}
-	blobIndex := 0
-	fieldIndex := -1
-	for i := 0; i < len(data); i += 31 {
-		fieldIndex++
-		if fieldIndex == params.BlobTxFieldElementsPerBlob {
-			blobs = append(blobs, kzg4844.Blob{})
-			blobIndex++
-			fieldIndex = 0
-		}
-		max := i + 31
-		if max > len(data) {
-			max = len(data)
-		}
-		copy(blobs[blobIndex][fieldIndex*32:], data[i:max])
-	}
-	return blobs
+// EncodeOptions controls how EncodeBlobsWithOptions builds blobs.
+type EncodeOptions struct {
+	// Canonical treats the input as raw blobs instead of encoding it.
+	Canonical bool
+	// Pad zero pads a trailing partial blob of canonical input instead of
+	// rejecting it.
+	Pad bool
+	// Parallelism is the number of workers computing KZG data.
+	Parallelism int
+	// Compression is applied to the data before it is packed into blobs.
+	Compression Compression
+	// Format is the field element packing of the encoded blobs.
+	Format BlobFormat
+	// SkipBlobProofs leaves out the blob proofs for callers that only need
+	// commitments and versioned hashes.
+	SkipBlobProofs bool
+	// SegmentProofs also computes the dill-das segment proofs. They are the
+	// most expensive part and only computed for callers that need them.
+	SegmentProofs bool
+	// Cache stores computed bundles on disk, nil disables caching.
+	Cache *BundleCache
+	// LogTimings logs the time spent in each KZG stage.
+	LogTimings bool
 }
 
-func EncodeBlobs(data []byte, canonical ...bool) ([]kzg4844.Blob, []kzg4844.Commitment, []kzg4844.Proof, []kzg4844.Proof, []common.Hash, error) {
-	var (
-		blobs           []kzg4844.Blob
-		commits         []kzg4844.Commitment
-		proofs          []kzg4844.Proof
-		extraProofs     []kzg4844.Proof
-		versionedHashes []common.Hash
-	)
+// DefaultEncodeOptions returns the options used by EncodeBlobs. Bundles are
+// not cached unless Cache is set.
+func DefaultEncodeOptions() EncodeOptions {
+	return EncodeOptions{Parallelism: runtime.NumCPU()}
+}
 
-	if len(canonical) != 0 && canonical[0] {
-		blobSize := 4096 * 32
-		for i := 0; i < len(data)/blobSize; i++ {
-			blobs = append(blobs, kzg4844.Blob(data[i*blobSize:(i+1)*blobSize]))
-		}
-	} else {
-		blobs = encodeBlobs(data)
-	}
-	for _, blob := range blobs {
-		commit, err := kzg4844.BlobToCommitment(blob)
-		if err != nil {
-			return nil, nil, nil, nil, nil, err
-		}
-		commits = append(commits, commit)
+func EncodeBlobs(data []byte, canonical ...bool) (*BlobBundle, error) {
+	opts := DefaultEncodeOptions()
+	opts.Canonical = len(canonical) != 0 && canonical[0]
+	return EncodeBlobsWithOptions(data, opts)
+}
+
+func EncodeBlobsWithOptions(data []byte, opts EncodeOptions) (*BlobBundle, error) {
+	var blobs []kzg4844.Blob
 
-		proof, err := kzg4844.ComputeBlobProof(blob, commit)
+	if opts.Canonical {
+		var err error
+		blobs, err = canonicalBlobs(data, opts.Pad)
 		if err != nil {
-			return nil, nil, nil, nil, nil, err
+			return nil, err
 		}
-		proofs = append(proofs, proof)
-		ep, err := das.BlobToSegmentsProofOnly(blob[:])
+	} else {
+		payload, err := compressPayload(data, opts.Compression)
 		if err != nil {
-			return nil, nil, nil, nil, nil, err
-		}
-		for _, p := range ep {
-			extraProofs = append(extraProofs, kzg4844.Proof(das.MarshalProof(&p)))
+			return nil, err
 		}
-		versionedHashes = append(versionedHashes, kZGToVersionedHash(commit))
+		blobs = encodeBlobs(payload, opts.Format, opts.Compression)
 	}
-	return blobs, commits, proofs, extraProofs, versionedHashes, nil
+	return bundleFor(blobs, opts)
 }
 
 var blobCommitmentVersionKZG uint8 = 0x01
@@ -95,29 +90,6 @@ func kZGToVersionedHash(kzg kzg4844.Commitment) common.Hash {
 	return h
 }
 
-func DecodeBlob(blob []byte) []byte {
-	if len(blob) != params.BlobTxFieldElementsPerBlob*32 {
-		panic("invalid blob encoding")
-	}
-	var data []byte
-
-	// XXX: the following removes trailing 0s in each field element (see EncodeBlobs), which could be unexpected for certain blobs
-	j := 0
-	for i := 0; i < params.BlobTxFieldElementsPerBlob; i++ {
-		data = append(data, blob[j:j+31]...)
-		j += 32
-	}
-
-	i := len(data) - 1
-	for ; i >= 0; i-- {
-		if data[i] != 0x00 {
-			break
-		}
-	}
-	data = data[:i+1]
-	return data
-}
-
 func DecodeUint256String(hexOrDecimal string) (*uint256.Int, error) {
 	var base = 10
 	if strings.HasPrefix(hexOrDecimal, "0x") {
@@ -230,8 +202,13 @@ func sendTxAndWait(ctx context.Context, client *ethclient.Client, tx *types.Tran
 	if err != nil {
 		return err
 	}
+	return waitForReceipt(client, tx.Hash(), start)
+}
+
+// waitForReceipt polls the receipt of the sent tx hash until it is included.
+func waitForReceipt(client *ethclient.Client, hash common.Hash, start time.Time) error {
 	for {
-		_, err := client.TransactionReceipt(context.Background(), tx.Hash())
+		_, err := client.TransactionReceipt(context.Background(), hash)
 		if err == ethereum.NotFound {
 			time.Sleep(4 * time.Second)
 			continue
@@ -241,27 +218,18 @@ func sendTxAndWait(ctx context.Context, client *ethclient.Client, tx *types.Tran
 		}
 		break
 	}
-	log.Printf("tx %s included, time used %fs", tx.Hash().String(), time.Since(start).Seconds())
+	log.Printf("tx %s included, time used %fs", hash.String(), time.Since(start).Seconds())
 	return nil
 }
 
-type blobsStruct struct {
-	blobs           []kzg4844.Blob
-	comms           []kzg4844.Commitment
-	proofs          []kzg4844.Proof
-	versionedHashes []common.Hash
-}
-
-func randomBlobs(cnt int) blobsStruct {
+// randomBlobs returns cnt blobs of random field elements and their KZG data
+// selected by opts. The blobs are never cached.
+func randomBlobs(cnt int, opts EncodeOptions) *BlobBundle {
 	data := RandomFrData(4096 * 32 * cnt)
-	blobs, commitments, proofs, _, versionedHashes, err := EncodeBlobs(data, true)
+	opts.Canonical, opts.Pad, opts.Cache = true, false, nil
+	bundle, err := EncodeBlobsWithOptions(data, opts)
 	if err != nil {
 		log.Fatalf("failed to compute commitments: %v", err)
 	}
-	return blobsStruct{
-		blobs:           blobs,
-		comms:           commitments,
-		proofs:          proofs,
-		versionedHashes: versionedHashes,
-	}
+	return bundle
 }
@@ -1,7 +1,11 @@
 package main
 
 import (
+	"bytes"
+	"encoding/binary"
 	"testing"
+
+	"github.com/stretchr/testify/require"
 )
 
 func makeBlob(siz int) []byte {
@@ -13,24 +17,83 @@ func makeBlob(siz int) []byte {
 }
 
 func TestBlobCodec(t *testing.T) {
+	for _, format := range []BlobFormat{BlobFormatPacked31, BlobFormatPacked254} {
+		size := format.payloadSize()
+		testCases := []struct {
+			name  string
+			data  []byte
+			blobs int
+		}{
+			{"empty", []byte{}, 1},
+			{"small", []byte("hello 12\n"), 1},
+			{"trailing zeros", append(makeBlob(100), make([]byte, 40)...), 1},
+			{"only zeros", make([]byte, 62), 1},
+			{"ones", bytes.Repeat([]byte{0xff}, 1000), 1},
+			{"full blob", makeBlob(size), 1},
+			{"two blobs", makeBlob(size + 10), 2},
+			{"three blobs with trailing zeros", append(makeBlob(2*size), make([]byte, 31)...), 3},
+		}
 
+		for _, tc := range testCases {
+			t.Run(format.String()+"/"+tc.name, func(t *testing.T) {
+				blobs := encodeBlobs(tc.data, format, CompressionNone)
+				require.Len(t, blobs, tc.blobs)
+				for _, blob := range blobs {
+					for i := 0; i < blobSize; i += fieldElementSize {
+						require.Zero(t, blob[i]&0xc0, "field element %d exceeds 254 bits", i/fieldElementSize)
+						if format == BlobFormatPacked31 {
+							require.Zero(t, blob[i], "field element %d exceeds 31 bytes", i/fieldElementSize)
+						}
+					}
+				}
+				dec, err := DecodeBlobs(blobsToBytes(blobs))
+				require.NoError(t, err)
+				require.True(t, bytes.Equal(tc.data, dec), "expected %d bytes, got %d", len(tc.data), len(dec))
+			})
+		}
+	}
 }
 
-/*
-func TestBlobsCodec(t *testing.T) {
-	blob := makeBlob(params.FieldElementsPerBlob*32 + 10)
-	encB := EncodeBlobs(blob)
-	if len(encB) != 2 {
-		t.Fatal("expected 2 blobs, got", len(encB))
-	}
-	dec1 := DecodeBlob(encB[0][:])
-	dec2 := DecodeBlob(encB[1][:])
-	dec := append(dec1, dec2...)
-	if len(dec) != len(blob) {
-		t.Fatalf("mismatched lengths: expected %d, got %d", len(blob), len(dec))
-	}
-	if !bytes.Equal(blob, dec) {
-		t.Fatalf("expected %x, got %x", blob, dec)
+func TestPacked254Density(t *testing.T) {
+	require.Equal(t, 130016, blobPayloadSize254)
+	require.Len(t, encodeBlobs(makeBlob(50*blobPayloadSize), BlobFormatPacked254, CompressionNone), 49)
+	require.Equal(t, 49, BlobFormatPacked254.blobsNeeded(50*blobPayloadSize))
+	require.Equal(t, 50, BlobFormatPacked31.blobsNeeded(50*blobPayloadSize))
+
+	// 4 field elements carry 127 bytes
+	var blob [5 * fieldElementSize]byte
+	pack254(blob[fieldElementSize:], bytes.Repeat([]byte{0xff}, 127))
+	for i := 1; i < 5; i++ {
+		elem := blob[i*fieldElementSize : (i+1)*fieldElementSize]
+		require.Equal(t, byte(0x3f), elem[0])
+		require.Equal(t, bytes.Repeat([]byte{0xff}, 31), elem[1:])
 	}
 }
-*/
+
+func TestDecodeLegacyBlob(t *testing.T) {
+	data := []byte("legacy blob without header")
+	blob := make([]byte, blobSize)
+	copy(blob, data)
+
+	dec, err := DecodeBlob(blob)
+	require.NoError(t, err)
+	require.Equal(t, data, dec)
+}
+
+func TestDecodeBlobErrors(t *testing.T) {
+	_, err := DecodeBlob(make([]byte, 10))
+	require.Error(t, err)
+
+	blob := encodeBlob([]byte("abc"), BlobFormatPacked31, CompressionNone)
+	blob[blobHeaderVersionOffset] = blobCodecVersion + 1
+	_, err = DecodeBlob(blob[:])
+	require.Error(t, err)
+
+	blob = encodeBlob([]byte("abc"), BlobFormatPacked31, CompressionNone)
+	binary.BigEndian.PutUint64(blob[blobHeaderLengthOffset:], blobPayloadSize+1)
+	_, err = DecodeBlob(blob[:])
+	require.Error(t, err)
+
+	_, err = DecodeBlobs(nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DillLabs/dill-blob-utils/hex"
+	"github.com/DillLabs/dill-execution/common"
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/urfave/cli"
+)
+
+// blobClaim is a blob together with the KZG data claimed for it. Claims that
+// are nil were not provided and are computed instead of checked. Raw blobs
+// come without any claims, so only their field elements are checked.
+type blobClaim struct {
+	name          string
+	raw           bool
+	blob          kzg4844.Blob
+	commitment    *kzg4844.Commitment
+	proof         *kzg4844.Proof
+	versionedHash *common.Hash
+	segmentProofs []kzg4844.Proof
+}
+
+// VerifyApp checks every blob of the given sidecar JSON files and raw blob
+// files and prints PASS or FAIL per blob. It fails if any blob fails, so it
+// can gate CI jobs.
+func VerifyApp(cliCtx *cli.Context) error {
+	segments := cliCtx.Bool(VerifySegmentsFlag.Name)
+	files := cliCtx.Args()
+	if len(files) == 0 {
+		return errors.New("no sidecar or blob files given")
+	}
+
+	claims, err := loadBlobClaims(files)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for i := range claims {
+		if err := verifyBlobClaim(&claims[i], segments); err != nil {
+			failed++
+			fmt.Printf("%s: FAIL: %v\n", claims[i].name, err)
+			continue
+		}
+		if claims[i].raw {
+			fmt.Printf("%s: PASS field elements only, raw blob has no commitment or proof to check, computed commitment %x versioned hash %v\n",
+				claims[i].name, claims[i].commitment[:], *claims[i].versionedHash)
+			continue
+		}
+		fmt.Printf("%s: PASS commitment %x versioned hash %v\n", claims[i].name, claims[i].commitment[:], *claims[i].versionedHash)
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d of %d blobs failed verification", failed, len(claims))
+	}
+	return nil
+}
+
+// verifyBlobClaim checks c, filling in the commitment and versioned hash if
+// they were not provided. A missing proof is not computed, since checking a
+// proof derived from the blob itself proves nothing.
+func verifyBlobClaim(c *blobClaim, segments bool) error {
+	if err := checkCanonical([]kzg4844.Blob{c.blob}, 0); err != nil {
+		return err
+	}
+	if c.commitment == nil {
+		commit, err := kzg4844.BlobToCommitment(c.blob)
+		if err != nil {
+			return fmt.Errorf("failed to compute commitment: %v", err)
+		}
+		c.commitment = &commit
+	}
+	if c.proof != nil {
+		if err := kzg4844.VerifyBlobProof(c.blob, *c.commitment, *c.proof); err != nil {
+			return fmt.Errorf("blob proof does not match commitment: %v", err)
+		}
+	}
+	hash := kZGToVersionedHash(*c.commitment)
+	if c.versionedHash != nil && *c.versionedHash != hash {
+		return fmt.Errorf("versioned hash %v does not match commitment, expected %v", *c.versionedHash, hash)
+	}
+	c.versionedHash = &hash
+
+	if !segments {
+		return nil
+	}
+	if c.segmentProofs == nil {
+		return errors.New("no segment proofs to check")
+	}
+	proofs, err := computeSegmentProofs(&c.blob)
+	if err != nil {
+		return fmt.Errorf("failed to compute segment proofs: %v", err)
+	}
+	if len(c.segmentProofs) != len(proofs) {
+		return fmt.Errorf("%d segment proofs, expected %d", len(c.segmentProofs), len(proofs))
+	}
+	for i := range proofs {
+		if c.segmentProofs[i] != proofs[i] {
+			return fmt.Errorf("segment proof %d does not match", i)
+		}
+	}
+	return nil
+}
+
+// loadBlobClaims reads the blobs of every file in order. Files ending in
+// .json are sidecars, anything else holds raw blobs.
+func loadBlobClaims(files []string) ([]blobClaim, error) {
+	var claims []blobClaim
+	for _, file := range files {
+		var (
+			c   []blobClaim
+			err error
+		)
+		if strings.EqualFold(filepath.Ext(file), ".json") {
+			c, err = sidecarClaims(file)
+		} else {
+			c, err = blobFileClaims(file)
+		}
+		if err != nil {
+			return nil, err
+		}
+		claims = append(claims, c...)
+	}
+	return claims, nil
+}
+
+// sidecarClaims reads the blobs and claims of a sidecar JSON file.
+func sidecarClaims(file string) ([]blobClaim, error) {
+	sc, err := readSidecarJSON(file)
+	if err != nil {
+		return nil, err
+	}
+	claims := make([]blobClaim, 0, len(sc.Blobs))
+	for _, e := range sc.Blobs {
+		c := blobClaim{name: fmt.Sprintf("%s blob %d", file, e.Index)}
+		if c.blob, err = e.loadBlob(filepath.Dir(file)); err != nil {
+			return nil, fmt.Errorf("%s: %v", file, err)
+		}
+		var commit kzg4844.Commitment
+		if err := decodeFixedHex(commit[:], e.KZGCommitment); err != nil {
+			return nil, fmt.Errorf("%s: invalid kzg_commitment: %v", c.name, err)
+		}
+		var proof kzg4844.Proof
+		if err := decodeFixedHex(proof[:], e.KZGProof); err != nil {
+			return nil, fmt.Errorf("%s: invalid kzg_proof: %v", c.name, err)
+		}
+		c.commitment, c.proof = &commit, &proof
+		// beacon API sidecars have no versioned hash
+		if e.VersionedHash != "" {
+			var hash common.Hash
+			if err := decodeFixedHex(hash[:], e.VersionedHash); err != nil {
+				return nil, fmt.Errorf("%s: invalid versioned_hash: %v", c.name, err)
+			}
+			c.versionedHash = &hash
+		}
+		for i, s := range e.SegmentProofs {
+			var p kzg4844.Proof
+			if err := decodeFixedHex(p[:], s); err != nil {
+				return nil, fmt.Errorf("%s: invalid segment proof %d: %v", c.name, i, err)
+			}
+			c.segmentProofs = append(c.segmentProofs, p)
+		}
+		claims = append(claims, c)
+	}
+	return claims, nil
+}
+
+// blobFileClaims splits a raw blob file into blobs without any claims.
+func blobFileClaims(file string) ([]blobClaim, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading blob file: %v", err)
+	}
+	if len(data) == 0 || len(data)%blobSize != 0 {
+		return nil, fmt.Errorf("%s has %d bytes, expected a multiple of %d", file, len(data), blobSize)
+	}
+	claims := make([]blobClaim, len(data)/blobSize)
+	for i := range claims {
+		claims[i] = blobClaim{
+			name: fmt.Sprintf("%s blob %d", file, i),
+			raw:  true,
+			blob: kzg4844.Blob(data[i*blobSize : (i+1)*blobSize]),
+		}
+	}
+	return claims, nil
+}
+
+// decodeFixedHex decodes s into dst, which it must fill exactly.
+func decodeFixedHex(dst []byte, s string) error {
+	b, err := hex.DecodeHex(s)
+	if err != nil {
+		return err
+	}
+	if len(b) != len(dst) {
+		return fmt.Errorf("got %d bytes, expected %d", len(b), len(dst))
+	}
+	copy(dst, b)
+	return nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+
+	"github.com/DillLabs/dill-blob-utils/hex"
+	ethereum "github.com/DillLabs/dill-execution"
+	"github.com/DillLabs/dill-execution/common"
+	"github.com/DillLabs/dill-execution/crypto/kzg4844"
+	"github.com/DillLabs/dill-execution/ethclient"
+	"github.com/DillLabs/dill-execution/params"
+	"github.com/urfave/cli"
+)
+
+// pointEvaluationPrecompile is the address of the EIP-4844 point evaluation
+// precompile.
+var pointEvaluationPrecompile = common.BytesToAddress([]byte{0x0a})
+
+// pointEvaluationReturn is the output of a successful call to the point
+// evaluation precompile: FIELD_ELEMENTS_PER_BLOB and the BLS modulus, each
+// as a 32 byte big endian integer.
+func pointEvaluationReturn() []byte {
+	ret := make([]byte, 64)
+	binary.BigEndian.PutUint64(ret[24:32], params.BlobTxFieldElementsPerBlob)
+	copy(ret[64-len(blsModulus):], blsModulus)
+	return ret
+}
+
+// VerifyPointApp checks point evaluations written by the proof command, or
+// given as raw precompile inputs, locally and, unless local is set, against
+// the point evaluation precompile of the node at rpc-url.
+func VerifyPointApp(cliCtx *cli.Context) error {
+	addr := cliCtx.String(TxRPCURLFlag.Name)
+	input := cliCtx.String(VerifyPointInputFlag.Name)
+	rawInputs := cliCtx.StringSlice(VerifyPointEvalInputFlag.Name)
+	local := cliCtx.Bool(VerifyPointLocalFlag.Name)
+
+	var inputs [][]byte
+	if input != "" {
+		r, err := openInput(input)
+		if err != nil {
+			return fmt.Errorf("error reading input file: %v", err)
+		}
+		defer r.Close()
+		b, err := io.ReadAll(r)
+		if err != nil {
+			return fmt.Errorf("error reading input file: %v", err)
+		}
+		var evals []pointEvaluationJSON
+		if err := json.Unmarshal(b, &evals); err != nil {
+			return fmt.Errorf("invalid point evaluations: %v", err)
+		}
+		for _, e := range evals {
+			rawInputs = append(rawInputs, e.PointEvalInput)
+		}
+	}
+	for i, raw := range rawInputs {
+		b, err := hex.DecodeHex(raw)
+		if err != nil {
+			return fmt.Errorf("invalid point evaluation input %d: %v", i, err)
+		}
+		inputs = append(inputs, b)
+	}
+	if len(inputs) == 0 {
+		return errors.New("no point evaluations given")
+	}
+
+	var (
+		ctx    = context.Background()
+		client *ethclient.Client
+	)
+	if !local {
+		var err error
+		if client, err = ethclient.DialContext(ctx, addr); err != nil {
+			log.Fatalf("Failed to connect to the Ethereum client: %v", err)
+		}
+	}
+
+	failed := 0
+	for i, in := range inputs {
+		// both checks always run, so a KZG setup mismatch between this tool
+		// and the node shows up as differing results
+		localErr := verifyPointEvaluationLocal(in)
+		result := "local " + pointCheckResult(localErr)
+		var precompileErr error
+		if client != nil {
+			precompileErr = verifyPointEvaluationPrecompile(ctx, client, in)
+			result += ", precompile " + pointCheckResult(precompileErr)
+			if (localErr == nil) != (precompileErr == nil) {
+				result += ", MISMATCH between local and precompile result"
+			}
+		}
+		if localErr != nil || precompileErr != nil {
+			failed++
+		}
+		fmt.Printf("point evaluation %d: %s\n", i, result)
+	}
+	if failed != 0 {
+		return fmt.Errorf("%d of %d point evaluations failed verification", failed, len(inputs))
+	}
+	return nil
+}
+
+// pointCheckResult formats the outcome of one check.
+func pointCheckResult(err error) string {
+	if err != nil {
+		return fmt.Sprintf("FAIL (%v)", err)
+	}
+	return "PASS"
+}
+
+// verifyPointEvaluationLocal checks the 192 byte precompile input in with
+// kzg4844.VerifyProof, applying the same checks as the precompile.
+func verifyPointEvaluationLocal(in []byte) error {
+	e, err := parsePointEvalInput(in)
+	if err != nil {
+		return err
+	}
+	if hash := kZGToVersionedHash(e.Commitment); hash != e.VersionedHash {
+		return fmt.Errorf("versioned hash %v does not match commitment, expected %v", e.VersionedHash, hash)
+	}
+	if err := kzg4844.VerifyProof(e.Commitment, e.Z, e.Y, e.Proof); err != nil {
+		return fmt.Errorf("proof verification failed: %v", err)
+	}
+	return nil
+}
+
+// verifyPointEvaluationPrecompile checks the precompile input in with an
+// eth_call to the point evaluation precompile of client. The input is sent as
+// is, even if it is malformed.
+func verifyPointEvaluationPrecompile(ctx context.Context, client *ethclient.Client, in []byte) error {
+	ret, err := client.CallContract(ctx, ethereum.CallMsg{To: &pointEvaluationPrecompile, Data: in}, nil)
+	if err != nil {
+		return fmt.Errorf("call failed: %v", err)
+	}
+	if want := pointEvaluationReturn(); !bytes.Equal(ret, want) {
+		return fmt.Errorf("returned %x, expected %x", ret, want)
+	}
+	return nil
+}
+
+// parsePointEvalInput splits a point evaluation precompile input into its
+// fields.
+func parsePointEvalInput(in []byte) (*pointEvaluation, error) {
+	if len(in) != 192 {
+		return nil, fmt.Errorf("point evaluation input has %d bytes, expected 192", len(in))
+	}
+	e := &pointEvaluation{}
+	copy(e.VersionedHash[:], in[0:32])
+	copy(e.Z[:], in[32:64])
+	copy(e.Y[:], in[64:96])
+	copy(e.Commitment[:], in[96:144])
+	copy(e.Proof[:], in[144:192])
+	return e, nil
+}
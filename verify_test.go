@@ -0,0 +1,79 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifySidecar(t *testing.T) {
+	dir := t.TempDir()
+	bundle, err := computeBlobBundle(encodeBlobs(makeBlob(blobPayloadSize+10), BlobFormatPacked31, CompressionNone), EncodeOptions{Parallelism: 2, SegmentProofs: true})
+	require.NoError(t, err)
+	blobFiles := []string{"blob-0000.bin", "blob-0001.bin"}
+	for i, file := range blobFiles {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), bundle.Blobs[i][:], 0644))
+	}
+	sc := newSidecarJSON(blobFiles, BlobFormatPacked31, bundle, true)
+	file := filepath.Join(dir, "sidecar.json")
+
+	// a sidecar built a bundle at a time numbers the blobs across bundles
+	var parts sidecarJSON
+	for i := range blobFiles {
+		part, err := computeBlobBundle(bundle.Blobs[i:i+1], EncodeOptions{SegmentProofs: true})
+		require.NoError(t, err)
+		parts.add(blobFiles[i:i+1], BlobFormatPacked31, part, true)
+	}
+	require.Equal(t, sc, &parts)
+
+	require.NoError(t, writeSidecarJSON(file, sc))
+
+	claims, err := sidecarClaims(file)
+	require.NoError(t, err)
+	require.Len(t, claims, 2)
+	for i := range claims {
+		require.NoError(t, verifyBlobClaim(&claims[i], true))
+	}
+
+	sc.Blobs[0].SegmentProofs = sc.Blobs[0].SegmentProofs[1:]
+	sc.Blobs[1].VersionedHash = "0x02" + sc.Blobs[1].VersionedHash[4:]
+	require.NoError(t, writeSidecarJSON(file, sc))
+	claims, err = sidecarClaims(file)
+	require.NoError(t, err)
+	require.NoError(t, verifyBlobClaim(&claims[0], false))
+	require.ErrorContains(t, verifyBlobClaim(&claims[0], true), "segment proofs")
+	require.ErrorContains(t, verifyBlobClaim(&claims[1], false), "versioned hash")
+
+	claims, err = blobFileClaims(filepath.Join(dir, blobFiles[0]))
+	require.NoError(t, err)
+	require.True(t, claims[0].raw)
+	require.ErrorContains(t, verifyBlobClaim(&claims[0], true), "no segment proofs")
+	require.NoError(t, verifyBlobClaim(&claims[0], false))
+	require.Equal(t, bundle.VersionedHashes()[0], *claims[0].versionedHash)
+
+	// sidecars without segment proofs fail when they are requested
+	sc = newSidecarJSON(blobFiles, BlobFormatPacked31, bundle, false)
+	require.NoError(t, writeSidecarJSON(file, sc))
+	claims, err = sidecarClaims(file)
+	require.NoError(t, err)
+	require.NoError(t, verifyBlobClaim(&claims[0], false))
+	require.ErrorContains(t, verifyBlobClaim(&claims[0], true), "no segment proofs")
+}
+
+func TestBundleVerify(t *testing.T) {
+	bundle, err := computeBlobBundle(encodeBlobs(makeBlob(blobPayloadSize+10), BlobFormatPacked31, CompressionNone), EncodeOptions{SkipBlobProofs: true})
+	require.NoError(t, err)
+	require.Nil(t, bundle.Proofs)
+	require.NoError(t, bundle.Verify())
+
+	bundle.Commitments[1][0] ^= 0x01
+	require.ErrorContains(t, bundle.Verify(), "blob 1")
+	require.ErrorContains(t, bundle.verify(false), "versioned hash")
+
+	// cache hits trust the commitments of bundles without blob proofs
+	bundle.versionedHashes[1] = kZGToVersionedHash(bundle.Commitments[1])
+	require.ErrorContains(t, bundle.Verify(), "blob 1")
+	require.NoError(t, bundle.verify(false))
+}